@@ -13,6 +13,7 @@ import (
 	"github.com/cfoust/cy/pkg/geom"
 	P "github.com/cfoust/cy/pkg/io/protocol"
 	"github.com/cfoust/cy/pkg/io/ws"
+	"github.com/cfoust/cy/pkg/taro"
 
 	"github.com/muesli/termenv"
 	"github.com/rs/zerolog/log"
@@ -129,6 +130,11 @@ func poll(conn cy.Connection) error {
 		return err
 	}
 	defer func() {
+		// The server enables the kitty keyboard protocol if the
+		// terminal supports it; this is a no-op otherwise
+		os.Stdout.WriteString(taro.PopKeyFlags)
+		// Panes may have changed the cursor's shape and color
+		os.Stdout.WriteString("\x1b[0 q\x1b]112\x07")
 		output.ExitAltScreen()
 		output.DisableMouseAllMotion()
 		term.Restore(int(os.Stdin.Fd()), oldState)
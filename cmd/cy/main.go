@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"net/http"
 	"os"
 	"runtime/pprof"
@@ -16,10 +17,13 @@ var CLI struct {
 
 	CPU   string `help:"Save a CPU performance report to the given path." name:"perf-file" optional:"" default:""`
 	Trace string `help:"Save a trace report to the given path." name:"trace-file" optional:"" default:""`
+
+	Connect struct{} `cmd:"" default:"1" help:"Connect to the cy server, starting it if necessary."`
+	Pick    PickCmd  `cmd:"" help:"Choose from the lines of standard input with cy's fuzzy finder and print them."`
 }
 
 func main() {
-	kong.Parse(&CLI,
+	ctx := kong.Parse(&CLI,
 		kong.Name("cy"),
 		kong.Description("the time traveling terminal multiplexer"),
 		kong.UsageOnError(),
@@ -42,6 +46,14 @@ func main() {
 		socketPath = label
 	}
 
+	if ctx.Command() == "pick" {
+		if err := pick(CLI.Pick); err != nil {
+			fmt.Fprintf(os.Stderr, "cy: %s\n", err)
+			os.Exit(2)
+		}
+		return
+	}
+
 	if daemon.WasReborn() {
 		cntx := new(daemon.Context)
 		_, err := cntx.Reborn()
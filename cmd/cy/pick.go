@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/cfoust/cy/pkg/fuzzy"
+	"github.com/cfoust/cy/pkg/geom"
+	P "github.com/cfoust/cy/pkg/io/protocol"
+	"github.com/cfoust/cy/pkg/io/ws"
+	"github.com/cfoust/cy/pkg/mux/stream/cli"
+	"github.com/cfoust/cy/pkg/mux/stream/renderer"
+
+	"github.com/xo/terminfo"
+	"golang.org/x/sys/unix"
+	"golang.org/x/term"
+)
+
+const (
+	// The maximum number of lines sent to the server in one message.
+	PICK_BATCH_SIZE = 1024
+	// How long to wait for more lines before sending a partial batch.
+	PICK_BATCH_INTERVAL = 50 * time.Millisecond
+)
+
+type PickCmd struct {
+	Prompt  string `help:"The text shown in the prompt." optional:"" default:""`
+	Multi   bool   `help:"Allow choosing more than one option." short:"m"`
+	Reverse bool   `help:"Show the options below the prompt."`
+}
+
+// readLines sends each line of `r` on the returned channel, which is
+// closed once there are no more lines.
+func readLines(ctx context.Context, r io.Reader) <-chan string {
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return lines
+}
+
+// sendLines sends `lines` to the server in batches.
+func sendLines(
+	ctx context.Context,
+	conn ws.Client[P.Message],
+	lines <-chan string,
+) error {
+	ticker := time.NewTicker(PICK_BATCH_INTERVAL)
+	defer ticker.Stop()
+
+	var batch []string
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case line, more := <-lines:
+			if !more {
+				return conn.Send(P.OptionsMessage{
+					Options: batch,
+					Done:    true,
+				})
+			}
+
+			batch = append(batch, line)
+			if len(batch) < PICK_BATCH_SIZE {
+				continue
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		}
+
+		err := conn.Send(P.OptionsMessage{Options: batch})
+		if err != nil {
+			return err
+		}
+		batch = nil
+	}
+}
+
+// pickPopup asks the cy server at `socketPath` to show the fuzzy finder to
+// the client using the pane this process is running in.
+func pickPopup(socketPath string, args PickCmd) ([]string, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := ws.Connect(ctx, P.Protocol, socketPath)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := unix.Getsid(0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = conn.Send(P.PickMessage{
+		Session: session,
+		Prompt:  args.Prompt,
+		Multi:   args.Multi,
+		Reverse: args.Reverse,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go sendLines(ctx, conn, readLines(ctx, os.Stdin))
+
+	events := conn.Receive()
+	for {
+		select {
+		case <-conn.Ctx().Done():
+			return nil, fmt.Errorf("the server closed the connection")
+		case packet, more := <-events:
+			if !more {
+				return nil, fmt.Errorf("the server closed the connection")
+			}
+
+			if packet.Error != nil {
+				return nil, packet.Error
+			}
+
+			switch msg := packet.Contents.(type) {
+			case *P.ChosenMessage:
+				return msg.Options, nil
+			case *P.ErrorMessage:
+				return nil, fmt.Errorf("%s", msg.Message)
+			}
+		}
+	}
+}
+
+// pickInline shows the fuzzy finder directly in the terminal.
+func pickInline(args PickCmd) ([]string, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer tty.Close()
+
+	columns, rows, err := term.GetSize(int(tty.Fd()))
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := terminfo.LoadFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	options := make(chan fuzzy.Option)
+	go func() {
+		defer close(options)
+		for line := range readLines(ctx, os.Stdin) {
+			select {
+			case options <- fuzzy.NewOption(line, line):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	result := make(chan interface{})
+	settings := []fuzzy.Setting{
+		fuzzy.WithResult(result),
+		fuzzy.WithPrompt(args.Prompt),
+		fuzzy.WithStream(options),
+	}
+
+	if args.Multi {
+		settings = append(settings, fuzzy.WithMulti)
+	}
+
+	if args.Reverse {
+		settings = append(settings, fuzzy.WithReverse)
+	}
+
+	finder := fuzzy.NewFuzzy(ctx, nil, settings...)
+	renderer := renderer.NewRenderer(
+		ctx,
+		info,
+		geom.Vec2{
+			R: rows,
+			C: columns,
+		},
+		finder,
+	)
+
+	// Attach only returns once the terminal has been restored
+	done := make(chan error)
+	go func() { done <- cli.Attach(ctx, renderer, tty, tty) }()
+
+	var match interface{}
+	select {
+	case match = <-result:
+	case err = <-done:
+		return nil, err
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		return nil, err
+	}
+
+	var chosen []string
+	switch match := match.(type) {
+	case string:
+		chosen = []string{match}
+	case []interface{}:
+		for _, option := range match {
+			if line, ok := option.(string); ok {
+				chosen = append(chosen, line)
+			}
+		}
+	}
+
+	return chosen, nil
+}
+
+// pick runs the fuzzy finder on the lines read from standard input and
+// prints the ones the user chose. Inside of cy, the fuzzy finder is shown
+// over the current pane.
+func pick(args PickCmd) error {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return fmt.Errorf("cy pick reads options from standard input")
+	}
+
+	var chosen []string
+	var err error
+	if socketPath, ok := os.LookupEnv(CY_PICK_SOCKET_ENV); ok {
+		chosen, err = pickPopup(socketPath, args)
+	} else {
+		chosen, err = pickInline(args)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Like fzf, exit with 130 when the user quit without choosing
+	if len(chosen) == 0 {
+		os.Exit(130)
+	}
+
+	for _, line := range chosen {
+		fmt.Println(line)
+	}
+
+	return nil
+}
@@ -35,6 +35,13 @@ func getLock(lockPath string) (*os.File, error) {
 }
 
 func serve(path string) error {
+	// Panes inherit this, which is how `cy pick` knows it is running
+	// inside of cy
+	err := os.Setenv(CY_PICK_SOCKET_ENV, path)
+	if err != nil {
+		return err
+	}
+
 	cy, err := cy.Start(context.Background(), cy.Options{
 		Config:  findConfig(),
 		DataDir: findDataDir(),
@@ -11,6 +11,10 @@ import (
 const (
 	CY_SOCKET_ENV      = "CY"
 	CY_SOCKET_TEMPLATE = "/tmp/cy-%d"
+	// The server sets this in the environment of every pane. Only `cy
+	// pick` reads it, so that other commands run inside of a pane, such
+	// as `cy -L other`, are not redirected to the outer server.
+	CY_PICK_SOCKET_ENV = "CY_PICK_SOCKET"
 )
 
 // Much of the socket creation code is ported from tmux. (see tmux.c)
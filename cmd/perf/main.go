@@ -5,9 +5,11 @@ import (
 	"io"
 	"os"
 	"runtime/pprof"
+	"strings"
 	"time"
 
 	"github.com/cfoust/cy/pkg/emu"
+	"github.com/cfoust/cy/pkg/fuzzy"
 	"github.com/cfoust/cy/pkg/geom"
 	"github.com/cfoust/cy/pkg/sessions"
 	"github.com/cfoust/cy/pkg/sessions/search"
@@ -33,6 +35,11 @@ var CLI struct {
 	} `cmd:"" help:"Search inside a borg file."`
 
 	Fake struct{} `cmd:"" help:"Search inside generated data."`
+
+	Fuzzy struct {
+		Path    string `help:"File whose lines will be used as options." type:"path" optional:""`
+		Options int    `help:"Number of options to generate if no file is provided." default:"100000"`
+	} `cmd:"" help:"Filter options with the fuzzy finder."`
 }
 
 func main() {
@@ -92,6 +99,9 @@ func main() {
 
 		fmt.Printf("%d matches in %d bytes", numMatches, desiredBytes)
 		events = sim.Events()
+	case "fuzzy":
+		benchmarkFuzzy()
+		return
 	default:
 		panic(ctx.Command())
 	}
@@ -102,20 +112,82 @@ func main() {
 
 	log.Info().Msgf("searching for %s in %d events", CLI.Query, len(events))
 
-	f, err := os.Create("search.prof")
+	defer startProfile("search.prof")()
+
+	results, err := search.Search(events, CLI.Query, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	log.Info().Msgf("found %d results", len(results))
+}
+
+// startProfile begins writing a CPU profile to `path` and returns a function
+// that stops it.
+func startProfile(path string) func() {
+	f, err := os.Create(path)
 	if err != nil {
 		panic(err)
 	}
-	defer f.Close()
 	if err := pprof.StartCPUProfile(f); err != nil {
 		panic(err)
 	}
-	defer pprof.StopCPUProfile()
 
-	results, err := search.Search(events, CLI.Query, nil)
-	if err != nil {
-		panic(err)
+	return func() {
+		pprof.StopCPUProfile()
+		f.Close()
 	}
+}
 
-	log.Info().Msgf("found %d results", len(results))
+func benchmarkFuzzy() {
+	var lines []string
+	if len(CLI.Fuzzy.Path) > 0 {
+		data, err := os.ReadFile(CLI.Fuzzy.Path)
+		if err != nil {
+			panic(err)
+		}
+		lines = strings.Split(string(data), "\n")
+	} else {
+		for i := 0; i < CLI.Fuzzy.Options; i++ {
+			lines = append(lines, fmt.Sprintf(
+				"pkg/module%d/src/file%d.go",
+				i%1000,
+				i,
+			))
+		}
+	}
+
+	options := make([]fuzzy.Option, len(lines))
+	for i, line := range lines {
+		options[i] = fuzzy.NewOption(line, line)
+	}
+
+	log.Info().Msgf(
+		"filtering %d options with %s",
+		len(options),
+		CLI.Query,
+	)
+
+	defer startProfile("fuzzy.prof")()
+
+	// Simulate the user typing the query one character at a time
+	var results []fuzzy.Option
+	start := time.Now()
+	for i := 1; i <= len(CLI.Query); i++ {
+		query := CLI.Query[:i]
+		queryStart := time.Now()
+		results = fuzzy.Filter(options, query)
+		log.Info().Msgf(
+			"%s: %d results in %s",
+			query,
+			len(results),
+			time.Since(queryStart),
+		)
+	}
+
+	log.Info().Msgf(
+		"found %d results in %s",
+		len(results),
+		time.Since(start),
+	)
 }
@@ -10,8 +10,11 @@ import (
 	_ "github.com/cfoust/cy/pkg/fuzzy/stories"
 	"github.com/cfoust/cy/pkg/geom"
 	"github.com/cfoust/cy/pkg/mux"
+	_ "github.com/cfoust/cy/pkg/mux/screen/prompt/stories"
 	_ "github.com/cfoust/cy/pkg/mux/screen/replay/stories"
 	_ "github.com/cfoust/cy/pkg/mux/screen/splash/stories"
+	_ "github.com/cfoust/cy/pkg/mux/screen/status/stories"
+	_ "github.com/cfoust/cy/pkg/mux/screen/whichkey/stories"
 	"github.com/cfoust/cy/pkg/mux/stream/cli"
 	"github.com/cfoust/cy/pkg/mux/stream/renderer"
 	"github.com/cfoust/cy/pkg/stories"
@@ -8,6 +8,14 @@ import (
 type Action struct {
 	Description string
 	Callback    *janet.Function
+	// Whether the action can be repeated without typing its prefix again
+	Repeat bool
+}
+
+var _ Repeatable = Action{}
+
+func (a Action) Repeatable() bool {
+	return a.Repeat
 }
 
 type BindScope = trie.Trie[Action]
@@ -5,12 +5,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/cfoust/cy/pkg/bind/trie"
 	"github.com/cfoust/cy/pkg/taro"
 	"github.com/stretchr/testify/assert"
 )
 
-func sendKeys(client *Engine[int], keys ...interface{}) {
-	msgs := make([]taro.KeyMsg, 0)
+func sendKeys[T any](client *Engine[T], keys ...interface{}) {
+	msgs := make([]input, 0)
 
 	for _, key := range keys {
 		switch key := key.(type) {
@@ -25,6 +26,8 @@ func sendKeys(client *Engine[int], keys ...interface{}) {
 			msgs = append(msgs, taro.KeyMsg{
 				Type: key,
 			})
+		case taro.MouseMsg:
+			msgs = append(msgs, key)
 		}
 	}
 
@@ -92,3 +95,161 @@ func TestIdle(t *testing.T) {
 
 	assert.Equal(t, engine.getState(), []string{})
 }
+
+// drain returns all of the events the engine has produced, excluding
+// PartialEvents.
+func drain[T any](engine *Engine[T]) (events []Event) {
+	for {
+		select {
+		case event := <-engine.Recv():
+			if _, ok := event.(PartialEvent[T]); ok {
+				continue
+			}
+			events = append(events, event)
+		default:
+			return
+		}
+	}
+}
+
+type testAction struct {
+	id     int
+	repeat bool
+}
+
+func (t testAction) Repeatable() bool {
+	return t.repeat
+}
+
+func TestRepeat(t *testing.T) {
+	engine := NewEngine[testAction]()
+	engine.SetRepeatTimeout(100 * time.Millisecond)
+
+	scope := NewScope[testAction]()
+	scope.Set([]interface{}{"ctrl+a", "n"}, testAction{id: 1, repeat: true})
+	scope.Set([]interface{}{"ctrl+a", "p"}, testAction{id: 2, repeat: true})
+	scope.Set([]interface{}{"ctrl+a", "x"}, testAction{id: 3})
+	engine.SetScopes(scope)
+	drain(engine)
+
+	sendKeys(engine, taro.KeyCtrlA, "nnp")
+	var ids []int
+	for _, event := range drain(engine) {
+		if action, ok := event.(ActionEvent[testAction]); ok {
+			ids = append(ids, action.Action.id)
+		}
+	}
+	assert.Equal(t, []int{1, 1, 2}, ids)
+
+	// Keys that do not repeat anything are handled normally
+	sendKeys(engine, "x")
+	events := drain(engine)
+	assert.Equal(t, 1, len(events))
+	assert.IsType(t, taro.KeyMsg{}, events[0])
+
+	// Non-repeatable actions cannot be repeated
+	sendKeys(engine, taro.KeyCtrlA, "xx")
+	events = drain(engine)
+	assert.Equal(t, 2, len(events))
+	assert.IsType(t, ActionEvent[testAction]{}, events[0])
+	assert.IsType(t, taro.KeyMsg{}, events[1])
+
+	// The window closes after the timeout
+	sendKeys(engine, taro.KeyCtrlA, "n")
+	drain(engine)
+	time.Sleep(200 * time.Millisecond)
+	sendKeys(engine, "n")
+	events = drain(engine)
+	assert.Equal(t, 1, len(events))
+	assert.IsType(t, taro.KeyMsg{}, events[0])
+}
+
+func TestCount(t *testing.T) {
+	engine := NewEngine[int]()
+	engine.SetCounts(true)
+
+	scope := NewScope[int]()
+	scope.Set([]interface{}{"ctrl+a", "n"}, 1)
+	scope.Set([]interface{}{"1"}, 2)
+	engine.SetScopes(scope)
+	drain(engine)
+
+	sendKeys(engine, "5", taro.KeyCtrlA, "n")
+	events := drain(engine)
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, 5, events[0].(ActionEvent[int]).Count)
+
+	// Zeroes can continue a count
+	sendKeys(engine, "20", taro.KeyCtrlA, "n")
+	events = drain(engine)
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, 20, events[0].(ActionEvent[int]).Count)
+
+	// Digits that are bound are not counts
+	sendKeys(engine, "1")
+	events = drain(engine)
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, 0, events[0].(ActionEvent[int]).Count)
+
+	// Counts that are not followed by a sequence are sent on
+	sendKeys(engine, "23x")
+	events = drain(engine)
+	assert.Equal(t, 3, len(events))
+	for i, char := range "23x" {
+		assert.Equal(t, []rune{char}, events[i].(taro.KeyMsg).Runes)
+	}
+}
+
+func TestMouse(t *testing.T) {
+	engine := NewEngine[int]()
+
+	scope := NewScope[int]()
+	scope.Set([]interface{}{"wheel-up"}, 1)
+	scope.Set([]interface{}{"ctrl+a", "mouse-left"}, 2)
+	re, err := trie.NewRegex(".")
+	assert.NoError(t, err)
+	scope.Set([]interface{}{"ctrl+b", re}, 3)
+	engine.SetScopes(scope)
+	drain(engine)
+
+	wheel := taro.MouseMsg{
+		Type:   taro.MousePress,
+		Button: taro.MouseWheelUp,
+		Down:   true,
+	}
+	click := taro.MouseMsg{
+		Type:   taro.MousePress,
+		Button: taro.MouseLeft,
+		Down:   true,
+	}
+	motion := taro.MouseMsg{
+		Type:   taro.MouseMotion,
+		Button: taro.MouseLeft,
+	}
+
+	sendKeys(engine, wheel)
+	events := drain(engine)
+	assert.Equal(t, 1, len(events))
+	event := events[0].(ActionEvent[int])
+	assert.Equal(t, 1, event.Action)
+	assert.Equal(t, wheel, *event.Mouse)
+
+	// Motion does not interrupt a sequence
+	sendKeys(engine, taro.KeyCtrlA, motion, click)
+	events = drain(engine)
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, motion, events[0])
+	assert.Equal(t, 2, events[1].(ActionEvent[int]).Action)
+
+	// Unbound clicks are passed on
+	sendKeys(engine, click)
+	events = drain(engine)
+	assert.Equal(t, []Event{click}, events)
+
+	// Regexes do not match mouse events
+	sendKeys(engine, taro.KeyCtrlB, wheel, "x")
+	events = drain(engine)
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, wheel, events[0])
+	assert.Equal(t, 3, events[1].(ActionEvent[int]).Action)
+}
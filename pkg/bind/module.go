@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/cfoust/cy/pkg/bind/trie"
+	"github.com/cfoust/cy/pkg/geom"
 	"github.com/cfoust/cy/pkg/taro"
 	"github.com/cfoust/cy/pkg/util"
 
@@ -24,6 +25,20 @@ type ActionEvent[T any] struct {
 	Sequence []string
 	// Any regex traversals that matched
 	Args []string
+	// The number the user typed before the sequence, or 0 if they did
+	// not type one
+	Count int
+	// The mouse event that completed the sequence, if any
+	Mouse *taro.MouseMsg
+}
+
+// Repeatable can be implemented by the actions stored in an Engine's scopes.
+// After a repeatable action is triggered by a sequence with more than one
+// key, the user can trigger it (or any other repeatable action with the
+// same prefix) again by typing just the last key of its sequence, as long
+// as they do so before the repeat timeout elapses.
+type Repeatable interface {
+	Repeatable() bool
 }
 
 type Match[T any] struct {
@@ -40,6 +55,17 @@ type PartialEvent[T any] struct {
 // Contains data for a single input event
 type input taro.Msg
 
+const (
+	// The default amount of time the user has to type the next key in
+	// a sequence.
+	DEFAULT_TIMEOUT = time.Second
+	// The default amount of time the user has to repeat a repeatable
+	// action.
+	DEFAULT_REPEAT_TIMEOUT = 500 * time.Millisecond
+	// The largest count the user can type before a sequence.
+	MAX_COUNT = 10000
+)
+
 type Engine[T any] struct {
 	deadlock.RWMutex
 
@@ -50,6 +76,24 @@ type Engine[T any] struct {
 
 	// Track the timeout for a user to enter another key
 	keyTimeout util.Lifetime
+	// How long the user has to enter the next key in a sequence
+	timeout time.Duration
+
+	// Track the timeout for a user to repeat an action
+	repeatTimeout util.Lifetime
+	// How long the user has to repeat a repeatable action
+	repeatDuration time.Duration
+	// The prefix of the last repeatable action, if it can still be
+	// repeated
+	repeat []string
+
+	// Whether typing digits before a sequence produces a count
+	countsEnabled bool
+	// The count the user has typed so far
+	count int
+	// The key presses that made up the count, which we send on if the
+	// keys that follow do not match anything
+	countKeys []input
 
 	// Holds the sequence of keys the user has entered
 	state []string
@@ -57,12 +101,43 @@ type Engine[T any] struct {
 
 func NewEngine[T any]() *Engine[T] {
 	return &Engine[T]{
-		in:         make(chan input),
-		out:        make(chan Event, 100),
-		keyTimeout: util.NewLifetime(context.Background()),
+		in:             make(chan input),
+		out:            make(chan Event, 100),
+		keyTimeout:     util.NewLifetime(context.Background()),
+		timeout:        DEFAULT_TIMEOUT,
+		repeatTimeout:  util.NewLifetime(context.Background()),
+		repeatDuration: DEFAULT_REPEAT_TIMEOUT,
 	}
 }
 
+// SetTimeout sets how long the user has to type the next key in a sequence
+// before the keys they have typed so far are discarded. It takes effect the
+// next time a key is pressed.
+func (e *Engine[T]) SetTimeout(timeout time.Duration) {
+	e.Lock()
+	e.timeout = timeout
+	e.Unlock()
+}
+
+// SetRepeatTimeout sets how long the user has to repeat a repeatable
+// action.
+func (e *Engine[T]) SetRepeatTimeout(timeout time.Duration) {
+	e.Lock()
+	e.repeatDuration = timeout
+	e.Unlock()
+}
+
+// SetCounts controls whether the engine interprets digits typed before a
+// sequence as a count, which is included in the resulting ActionEvent. A
+// digit only begins a count if it is not itself the start of a sequence.
+// The digits are held until the next key is pressed; if that key does not
+// begin a sequence, they are sent on as usual.
+func (e *Engine[T]) SetCounts(enabled bool) {
+	e.Lock()
+	e.countsEnabled = enabled
+	e.Unlock()
+}
+
 func (e *Engine[T]) Recv() <-chan Event {
 	return e.out
 }
@@ -78,76 +153,125 @@ func (e *Engine[T]) clearState() {
 
 	e.Lock()
 	e.state = make([]string, 0)
+	e.count = 0
+	e.countKeys = nil
 	e.Unlock()
 
 	e.out <- PartialEvent[T]{}
 }
 
-func (e *Engine[T]) setState(ctx context.Context, state []string) {
+// flushCount clears the engine's state and sends on any keys the user typed
+// as part of a count.
+func (e *Engine[T]) flushCount() {
+	e.RLock()
+	keys := e.countKeys
+	e.RUnlock()
+
+	e.clearState()
+	for _, key := range keys {
+		e.out <- key
+	}
+}
+
+// startTimeout flushes the engine's state if the user does not type
+// another key in time.
+func (e *Engine[T]) startTimeout(ctx context.Context) {
 	e.clearTimeout()
 
 	e.Lock()
-	e.state = state
 	e.keyTimeout = util.NewLifetime(ctx)
+	lifetime := e.keyTimeout
+	timeout := e.timeout
 	e.Unlock()
 
 	go func() {
-		timer := time.NewTimer(1 * time.Second)
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
 		select {
 		case <-timer.C:
-			e.clearState()
-		case <-e.keyTimeout.Ctx().Done():
+			e.flushCount()
+		case <-lifetime.Ctx().Done():
 			return
 		}
 	}()
 }
 
+func (e *Engine[T]) setState(ctx context.Context, state []string) {
+	e.Lock()
+	e.state = state
+	e.Unlock()
+
+	e.startTimeout(ctx)
+}
+
 func (e *Engine[T]) getState() []string {
 	e.RLock()
 	defer e.RUnlock()
 	return e.state
 }
 
-func (e *Engine[T]) processKey(ctx context.Context, in input) {
-	// Mouse messages have to be translated to match the pane, so we just
-	// pass them on
-	if _, ok := in.(taro.MouseMsg); ok {
-		e.out <- in
-		return
-	}
+func (e *Engine[T]) clearRepeat() {
+	e.Lock()
+	e.repeat = nil
+	e.Unlock()
 
-	key, ok := in.(taro.KeyMsg)
-	if !ok {
-		return
+	if !e.repeatTimeout.IsDone() {
+		e.repeatTimeout.Cancel()
 	}
+}
 
-	e.RLock()
-	state := e.state
-	scopes := e.scopes
-	e.RUnlock()
+// setRepeat lets the user repeat actions that begin with `prefix` until the
+// repeat timeout elapses.
+func (e *Engine[T]) setRepeat(ctx context.Context, prefix []string) {
+	e.clearRepeat()
 
-	sequence := append(state, key.String())
+	e.Lock()
+	e.repeat = prefix
+	e.repeatTimeout = util.NewLifetime(ctx)
+	lifetime := e.repeatTimeout
+	timeout := e.repeatDuration
+	e.Unlock()
 
-	// Later scopes override earlier ones
+	go func() {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			e.clearRepeat()
+		case <-lifetime.Ctx().Done():
+			return
+		}
+	}()
+}
+
+func isRepeatable[T any](value T) bool {
+	repeatable, ok := any(value).(Repeatable)
+	return ok && repeatable.Repeatable()
+}
+
+// match finds the action bound to `sequence`, if any. Later scopes override
+// earlier ones.
+func match[T any](scopes []*trie.Trie[T], sequence []string) (event ActionEvent[T], matched bool) {
 	for i := len(scopes) - 1; i >= 0; i-- {
 		scope := scopes[i]
-		value, re, matched := scope.Get(sequence)
-		if !matched {
+		value, re, ok := scope.Get(sequence)
+		if !ok {
 			continue
 		}
 
-		// Exact match, let's stop
-		e.clearState()
-		e.out <- ActionEvent[T]{
+		return ActionEvent[T]{
 			Action:   value,
 			Source:   scope,
 			Sequence: sequence,
 			Args:     re,
-		}
-		return
+		}, true
 	}
 
-	// Otherwise we might have a partial match
+	return
+}
+
+// partial finds all of the actions whose sequences begin with `sequence`.
+func partial[T any](scopes []*trie.Trie[T], sequence []string) []Match[T] {
 	matches := make([]Match[T], 0)
 	for i := len(scopes) - 1; i >= 0; i-- {
 		scope := scopes[i]
@@ -160,6 +284,136 @@ func (e *Engine[T]) processKey(ctx context.Context, in input) {
 		}
 	}
 
+	return matches
+}
+
+// trigger sends an ActionEvent and, if the action can be repeated, lets the
+// user repeat it.
+func (e *Engine[T]) trigger(ctx context.Context, event ActionEvent[T]) {
+	if isRepeatable(event.Action) && len(event.Sequence) > 1 {
+		prefix := event.Sequence[:len(event.Sequence)-1]
+		e.setRepeat(ctx, prefix)
+	}
+
+	e.out <- event
+}
+
+// processMouse triggers the action bound to a mouse event, if there is
+// one. Mouse events can only be the last step of a sequence and only match
+// literal steps, never regexes. Since they arrive constantly while the
+// mouse moves, they never interrupt a sequence the user is typing.
+func (e *Engine[T]) processMouse(ctx context.Context, mouse taro.MouseMsg) {
+	key, ok := taro.MouseEvent(mouse).Key()
+	if !ok {
+		e.out <- mouse
+		return
+	}
+
+	e.RLock()
+	state := e.state
+	scopes := e.scopes
+	count := e.count
+	e.RUnlock()
+
+	sequence := make([]string, len(state), len(state)+1)
+	copy(sequence, state)
+	sequence = append(sequence, key)
+
+	event, matched := match(scopes, sequence)
+	numArgs := len(event.Args)
+	if !matched || (numArgs > 0 && event.Args[numArgs-1] == key) {
+		// Mouse messages that are not bound have to be translated to
+		// match the pane, so we just pass them on
+		e.out <- mouse
+		return
+	}
+
+	event.Count = count
+	event.Mouse = &mouse
+	e.clearRepeat()
+	e.clearState()
+	e.trigger(ctx, event)
+}
+
+func (e *Engine[T]) processKey(ctx context.Context, in input) {
+	if mouse, ok := in.(taro.MouseMsg); ok {
+		e.processMouse(ctx, mouse)
+		return
+	}
+
+	// Replies to queries we sent to the terminal are handled elsewhere
+	if _, ok := in.(taro.KeyFlagsMsg); ok {
+		e.out <- in
+		return
+	}
+
+	key, ok := in.(taro.KeyMsg)
+	if !ok {
+		return
+	}
+
+	// Key releases never trigger bindings, but the pane may want them
+	if key.Event == taro.KeyEventRelease {
+		e.out <- in
+		return
+	}
+
+	e.RLock()
+	state := e.state
+	scopes := e.scopes
+	repeat := e.repeat
+	count := e.count
+	countsEnabled := e.countsEnabled
+	e.RUnlock()
+
+	keyStr := key.String()
+
+	sequence := make([]string, len(state), len(state)+1)
+	copy(sequence, state)
+	sequence = append(sequence, keyStr)
+
+	// The user may be repeating the last action
+	if len(state) == 0 && count == 0 && repeat != nil {
+		repeated := make([]string, len(repeat), len(repeat)+1)
+		copy(repeated, repeat)
+		repeated = append(repeated, keyStr)
+
+		event, matched := match(scopes, repeated)
+		if matched && isRepeatable(event.Action) {
+			e.trigger(ctx, event)
+			return
+		}
+	}
+
+	e.clearRepeat()
+
+	// Digits before a sequence are a count, provided that they are not
+	// bound to anything themselves
+	if countsEnabled && len(state) == 0 && len(keyStr) == 1 && keyStr[0] >= '0' && keyStr[0] <= '9' {
+		digit := int(keyStr[0] - '0')
+		_, matched := match(scopes, sequence)
+		isCount := count > 0 || (digit != 0 && !matched && len(partial(scopes, sequence)) == 0)
+
+		if isCount {
+			e.Lock()
+			e.count = geom.Min(e.count*10+digit, MAX_COUNT)
+			e.countKeys = append(e.countKeys, in)
+			e.Unlock()
+			e.startTimeout(ctx)
+			return
+		}
+	}
+
+	// Exact match, let's stop
+	if event, matched := match(scopes, sequence); matched {
+		event.Count = count
+		e.clearState()
+		e.trigger(ctx, event)
+		return
+	}
+
+	// Otherwise we might have a partial match
+	matches := partial(scopes, sequence)
 	if len(matches) > 0 {
 		e.out <- PartialEvent[T]{
 			Prefix:  sequence,
@@ -169,12 +423,13 @@ func (e *Engine[T]) processKey(ctx context.Context, in input) {
 		return
 	}
 
-	e.clearState()
+	e.flushCount()
 	e.out <- in
 }
 
 func (e *Engine[T]) SetScopes(scopes ...*trie.Trie[T]) {
 	e.clearState()
+	e.clearRepeat()
 	e.Lock()
 	e.scopes = scopes
 	e.Unlock()
@@ -0,0 +1,57 @@
+package bind
+
+import (
+	"sort"
+
+	"github.com/sasha-s/go-deadlock"
+)
+
+// Tables is a collection of named BindScopes, which are referred to as key
+// tables. A client can switch into a key table so that its bindings take
+// precedence over all others until the client switches out of it.
+type Tables struct {
+	deadlock.RWMutex
+	tables map[string]*BindScope
+}
+
+func NewTables() *Tables {
+	return &Tables{
+		tables: make(map[string]*BindScope),
+	}
+}
+
+// Create makes a new, empty key table called `name` and returns it. If a
+// table with that name already exists, it is returned instead.
+func (t *Tables) Create(name string) *BindScope {
+	t.Lock()
+	defer t.Unlock()
+
+	if scope, ok := t.tables[name]; ok {
+		return scope
+	}
+
+	scope := NewBindScope()
+	t.tables[name] = scope
+	return scope
+}
+
+// Get returns the key table called `name`, if it exists.
+func (t *Tables) Get(name string) (*BindScope, bool) {
+	t.RLock()
+	defer t.RUnlock()
+	scope, ok := t.tables[name]
+	return scope, ok
+}
+
+// Names returns the names of all of the key tables in alphabetical order.
+func (t *Tables) Names() []string {
+	t.RLock()
+	defer t.RUnlock()
+
+	names := make([]string, 0, len(t.tables))
+	for name := range t.tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -167,6 +167,38 @@ func (t *Trie[T]) Leaves() (leaves []Leaf[T]) {
 	return
 }
 
+// Walk calls `f` with every leaf accessible from this Trie and the sequence
+// that leads to it. Steps in the sequence are either strings or *Regex, just
+// like the sequences passed to Set. `f` must not modify the Trie.
+func (t *Trie[T]) Walk(f func(sequence []interface{}, value T)) {
+	t.RLock()
+	defer t.RUnlock()
+	t.walk(nil, f)
+}
+
+func (t *Trie[T]) walk(prefix []interface{}, f func([]interface{}, T)) {
+	visit := func(step interface{}, next interface{}) {
+		sequence := make([]interface{}, len(prefix), len(prefix)+1)
+		copy(sequence, prefix)
+		sequence = append(sequence, step)
+
+		switch next := next.(type) {
+		case T:
+			f(sequence, next)
+		case *Trie[T]:
+			next.walk(sequence, f)
+		}
+	}
+
+	for key, next := range t.next {
+		visit(key, next)
+	}
+
+	for _, re := range t.nextRe {
+		visit(re, re.next)
+	}
+}
+
 func strToInterface(slice []string) []interface{} {
 	query := make([]interface{}, 0)
 	for _, step := range slice {
@@ -254,3 +286,59 @@ func New[T any]() *Trie[T] {
 		nextRe: make(map[string]*Regex),
 	}
 }
+
+// Remove deletes the leaf at `sequence` or, if `sequence` is a prefix, every
+// leaf beneath it. Unlike Get, string steps are only compared against
+// literal keys and never matched against regex patterns. Remove returns
+// true if anything was deleted.
+func (t *Trie[T]) Remove(sequence []interface{}) bool {
+	t.Lock()
+	defer t.Unlock()
+	return t.remove(sequence)
+}
+
+func (t *Trie[T]) remove(sequence []interface{}) bool {
+	if len(sequence) == 0 {
+		return false
+	}
+
+	var (
+		next  interface{}
+		found bool
+	)
+	switch step := sequence[0].(type) {
+	case string:
+		next, found = t.next[step]
+	case *Regex:
+		var re *Regex
+		re, found = t.nextRe[step.Pattern]
+		if found {
+			next = re.next
+		}
+	}
+
+	if !found {
+		return false
+	}
+
+	if len(sequence) > 1 {
+		child, ok := next.(*Trie[T])
+		if !ok || !child.remove(sequence[1:]) {
+			return false
+		}
+
+		// Keep the child around if other leaves still need it
+		if len(child.next) > 0 || len(child.nextRe) > 0 {
+			return true
+		}
+	}
+
+	switch step := sequence[0].(type) {
+	case string:
+		delete(t.next, step)
+	case *Regex:
+		delete(t.nextRe, step.Pattern)
+	}
+
+	return true
+}
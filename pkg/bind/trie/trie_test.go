@@ -63,3 +63,47 @@ func TestRegex(t *testing.T) {
 	})
 	require.Equal(t, false, matched)
 }
+
+func TestWalk(t *testing.T) {
+	trie := New[int]()
+	trie.Set([]interface{}{"one", "two"}, 1)
+	trie.Set([]interface{}{"one", re("[abc]")}, 2)
+	trie.Set([]interface{}{"three"}, 3)
+
+	sequences := make(map[int][]interface{})
+	trie.Walk(func(sequence []interface{}, value int) {
+		sequences[value] = sequence
+	})
+
+	require.Equal(t, 3, len(sequences))
+	require.Equal(t, []interface{}{"one", "two"}, sequences[1])
+	require.Equal(t, "one", sequences[2][0])
+	require.Equal(t, "[abc]", sequences[2][1].(*Regex).Pattern)
+	require.Equal(t, []interface{}{"three"}, sequences[3])
+}
+
+func TestRemove(t *testing.T) {
+	trie := New[int]()
+	trie.Set([]interface{}{"one", "two"}, 1)
+	trie.Set([]interface{}{"one", "three"}, 2)
+	trie.Set([]interface{}{re("[abc]"), "t"}, 3)
+	trie.Set([]interface{}{"four"}, 4)
+
+	// String steps should not match regexes
+	require.False(t, trie.Remove([]interface{}{"a", "t"}))
+	require.False(t, trie.Remove([]interface{}{"one", "five"}))
+
+	require.True(t, trie.Remove([]interface{}{"one", "two"}))
+	_, _, matched := trie.Get([]string{"one", "two"})
+	require.False(t, matched)
+	require.Equal(t, 1, len(trie.Partial([]string{"one"})))
+
+	// Removing a prefix removes everything beneath it
+	require.True(t, trie.Remove([]interface{}{re("[abc]")}))
+	_, _, matched = trie.Get([]string{"a", "t"})
+	require.False(t, matched)
+
+	// Empty parents are pruned
+	require.True(t, trie.Remove([]interface{}{"one", "three"}))
+	require.Equal(t, 1, len(trie.Leaves()))
+}
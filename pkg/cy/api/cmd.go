@@ -1,13 +1,20 @@
 package api
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"os/exec"
+	"time"
 
 	"github.com/cfoust/cy/pkg/bind"
 	"github.com/cfoust/cy/pkg/cy/cmd"
 	"github.com/cfoust/cy/pkg/cy/params"
 	cyParams "github.com/cfoust/cy/pkg/cy/params"
+	"github.com/cfoust/cy/pkg/emu"
 	"github.com/cfoust/cy/pkg/janet"
+	"github.com/cfoust/cy/pkg/mux/screen"
 	"github.com/cfoust/cy/pkg/mux/screen/replayable"
 	"github.com/cfoust/cy/pkg/mux/screen/tree"
 	"github.com/cfoust/cy/pkg/mux/stream"
@@ -56,8 +63,15 @@ func (c *Cmd) New(
 		return 0, fmt.Errorf("param %s was not a string", params.ParamDataDirectory)
 	}
 
+	var historyLimit int
+	if value, ok := group.Params().Get(params.ParamHistoryLimit); ok {
+		historyLimit, _ = value.(int)
+	}
+
+	// The command stops when its pane is killed
+	lifetime := util.NewLifetime(c.Lifetime.Ctx())
 	replayable, err := cmd.New(
-		c.Lifetime.Ctx(),
+		lifetime.Ctx(),
 		stream.CmdOptions{
 			Command:   values.Command,
 			Args:      values.Args,
@@ -65,12 +79,19 @@ func (c *Cmd) New(
 		},
 		dataDir,
 		c.ReplayBinds,
+		emu.WithHistoryLimit(historyLimit),
 	)
 	if err != nil {
+		lifetime.Cancel()
 		return 0, err
 	}
 
-	pane := group.NewPane(c.Lifetime.Ctx(), replayable)
+	pane := group.NewPane(lifetime.Ctx(), replayable)
+	go func() {
+		<-pane.Ctx().Done()
+		lifetime.Cancel()
+	}()
+
 	if values.Name != "" {
 		pane.SetName(values.Name)
 	}
@@ -89,6 +110,14 @@ func (c *Cmd) Path(id tree.NodeID) (*string, error) {
 		return nil, fmt.Errorf("pane was not a cmd")
 	}
 
+	// Prefer the directory the shell told us about (via OSC 7), since
+	// the process we started may not be the one the user is using
+	if terminal, ok := r.Screen().(*screen.Terminal); ok {
+		if path := terminal.Directory(); path != "" {
+			return &path, nil
+		}
+	}
+
 	cmd, ok := r.Stream().(*stream.Cmd)
 	if !ok {
 		return nil, fmt.Errorf("pane was not a cmd")
@@ -101,3 +130,145 @@ func (c *Cmd) Path(id tree.NodeID) (*string, error) {
 
 	return &path, nil
 }
+
+func (c *Cmd) Host(id tree.NodeID) (*string, error) {
+	pane, ok := c.Tree.PaneById(id)
+	if !ok {
+		return nil, fmt.Errorf("pane not found: %d", id)
+	}
+
+	r, ok := pane.Screen().(*replayable.Replayable)
+	if !ok {
+		return nil, fmt.Errorf("pane was not a cmd")
+	}
+
+	terminal, ok := r.Screen().(*screen.Terminal)
+	if !ok {
+		return nil, nil
+	}
+
+	host := terminal.DirectoryHost()
+	if host == "" {
+		return nil, nil
+	}
+
+	return &host, nil
+}
+
+type RunParams struct {
+	// The directory in which to run the command
+	Dir string
+	// The maximum time in milliseconds the command may run for. Zero
+	// means there is no limit.
+	Timeout int
+}
+
+// The most output a command started by Run may write to each of stdout
+// and stderr.
+const maxRunOutput = 16 * 1024 * 1024
+
+// How long Run waits for the command's output to be closed after it exits
+// or is stopped. Processes the command left running in the background can
+// otherwise keep it open forever.
+const runWaitDelay = time.Second
+
+// runOutput collects the output of a command, refusing to store more than
+// maxRunOutput bytes.
+type runOutput struct {
+	// Not embedded, since io.Copy would use bytes.Buffer's ReadFrom and
+	// skip our Write
+	buffer   bytes.Buffer
+	exceeded bool
+}
+
+func (r *runOutput) Write(data []byte) (int, error) {
+	if r.buffer.Len()+len(data) > maxRunOutput {
+		r.exceeded = true
+		return 0, fmt.Errorf("output exceeded %d bytes", maxRunOutput)
+	}
+
+	return r.buffer.Write(data)
+}
+
+func (r *runOutput) String() string {
+	return r.buffer.String()
+}
+
+type RunResult struct {
+	Stdout string
+	Stderr string
+	Code   int
+}
+
+func (c *Cmd) Run(
+	ctx context.Context,
+	args []string,
+	params *janet.Named[RunParams],
+) (result RunResult, err error) {
+	if len(args) == 0 {
+		err = fmt.Errorf("no command provided")
+		return
+	}
+
+	values := params.Values()
+
+	// The command stops when either the caller or cy goes away
+	lifetime := util.NewLifetime(c.Lifetime.Ctx())
+	defer lifetime.Cancel()
+	go func() {
+		select {
+		case <-ctx.Done():
+			lifetime.Cancel()
+		case <-lifetime.Ctx().Done():
+		}
+	}()
+
+	runCtx := lifetime.Ctx()
+	if values.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(
+			runCtx,
+			time.Duration(values.Timeout)*time.Millisecond,
+		)
+		defer cancel()
+	}
+
+	var stdout, stderr runOutput
+	cmd := exec.CommandContext(runCtx, args[0], args[1:]...)
+	cmd.Dir = values.Dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = runWaitDelay
+
+	err = cmd.Run()
+	if runCtx.Err() != nil {
+		err = fmt.Errorf("%s was stopped: %s", args[0], runCtx.Err())
+		return
+	}
+
+	if stdout.exceeded || stderr.exceeded {
+		err = fmt.Errorf(
+			"%s wrote more than %d bytes of output",
+			args[0],
+			maxRunOutput,
+		)
+		return
+	}
+
+	// Neither exiting with a non-zero code nor leaving processes running in
+	// the background (which keep the command's output open) is an error
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) || errors.Is(err, exec.ErrWaitDelay) {
+		err = nil
+	}
+	if err != nil {
+		return
+	}
+
+	result = RunResult{
+		Stdout: stdout.String(),
+		Stderr: stderr.String(),
+		Code:   cmd.ProcessState.ExitCode(),
+	}
+	return
+}
@@ -6,6 +6,15 @@ import (
 	"github.com/cfoust/cy/pkg/janet"
 )
 
+//go:embed docs-cmd.md
+var DOCS_CMD string
+
+var _ janet.Documented = (*Cmd)(nil)
+
+func (i *Cmd) Documentation() string {
+	return DOCS_CMD
+}
+
 //go:embed docs-replay.md
 var DOCS_REPLAY string
 
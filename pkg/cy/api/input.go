@@ -1,17 +1,24 @@
 package api
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"os/exec"
 
 	cyParams "github.com/cfoust/cy/pkg/cy/params"
 	"github.com/cfoust/cy/pkg/fuzzy"
 	"github.com/cfoust/cy/pkg/geom"
 	"github.com/cfoust/cy/pkg/janet"
 	"github.com/cfoust/cy/pkg/mux/screen"
+	"github.com/cfoust/cy/pkg/mux/screen/prompt"
 	"github.com/cfoust/cy/pkg/mux/screen/server"
 	"github.com/cfoust/cy/pkg/mux/screen/tree"
+	"github.com/cfoust/cy/pkg/taro"
 	"github.com/cfoust/cy/pkg/util"
+
+	"github.com/rs/zerolog/log"
 )
 
 type InputModule struct {
@@ -25,6 +32,8 @@ type FuzzyParams struct {
 	Full     bool
 	Reverse  bool
 	Animated *bool
+	Multi    bool
+	Weights  []int
 }
 
 func (i *InputModule) Find(
@@ -42,9 +51,44 @@ func (i *InputModule) Find(
 		return nil, fmt.Errorf("missing client context")
 	}
 
-	options, err := fuzzy.UnmarshalOptions(choices)
-	if err != nil {
-		return nil, err
+	// Streams stop as soon as the user makes a choice
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var options []fuzzy.Option
+	var stream <-chan fuzzy.Option
+	var fiber *janet.Fiber
+	var command commandInput
+	if err := choices.Unmarshal(&fiber); err == nil {
+		stream = streamFiber(ctx, fiber)
+	} else if err := choices.Unmarshal(&command); err == nil {
+		stream, err = streamCommand(ctx, command)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		options, err = fuzzy.UnmarshalOptions(choices)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(params.Weights) > 0 {
+		if stream != nil {
+			return nil, fmt.Errorf("weights cannot be used with streams")
+		}
+
+		if len(params.Weights) != len(options) {
+			return nil, fmt.Errorf(
+				"got %d weights for %d options",
+				len(params.Weights),
+				len(options),
+			)
+		}
+
+		for i, weight := range params.Weights {
+			options[i].Weight = weight
+		}
 	}
 
 	shouldAnimate := true
@@ -68,6 +112,14 @@ func (i *InputModule) Find(
 		fuzzy.WithInline(geom.Vec2{R: cursor.Y, C: cursor.X}),
 	}
 
+	if stream != nil {
+		settings = append(settings, fuzzy.WithStream(stream))
+	}
+
+	if params.Multi {
+		settings = append(settings, fuzzy.WithMulti)
+	}
+
 	if (params.Animated == nil || (*params.Animated) == true) && shouldAnimate {
 		settings = append(settings, fuzzy.WithAnimation(state.Image))
 	}
@@ -93,3 +145,253 @@ func (i *InputModule) Find(
 		return nil, ctx.Err()
 	}
 }
+
+// showPrompt displays a prompt created by `create` next to the client's
+// cursor and waits for the user to respond.
+func showPrompt(
+	ctx context.Context,
+	user interface{},
+	create func(context.Context, ...prompt.Setting) *taro.Program,
+	settings ...prompt.Setting,
+) (interface{}, error) {
+	client, ok := user.(Client)
+	if !ok {
+		return nil, fmt.Errorf("missing client context")
+	}
+
+	// The prompt disappears if the caller stops waiting for it
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	outerLayers := client.OuterLayers()
+	cursor := outerLayers.State().Cursor
+	result := make(chan interface{})
+
+	program := create(
+		ctx,
+		append(
+			settings,
+			prompt.WithResult(result),
+			prompt.WithInline(geom.Vec2{R: cursor.Y, C: cursor.X}),
+		)...,
+	)
+
+	outerLayers.NewLayer(
+		program.Ctx(),
+		program,
+		screen.PositionTop,
+		screen.WithInteractive,
+	)
+
+	select {
+	case value := <-result:
+		return value, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+type TextParams struct {
+	Default     string
+	Placeholder string
+	Validate    *janet.Function
+}
+
+func (i *InputModule) Text(
+	ctx context.Context,
+	user interface{},
+	text string,
+	named *janet.Named[TextParams],
+) (*string, error) {
+	params := named.Values()
+
+	settings := []prompt.Setting{
+		prompt.WithPrompt(text),
+		prompt.WithDefault(params.Default),
+		prompt.WithPlaceholder(params.Placeholder),
+	}
+
+	if validate := params.Validate; validate != nil {
+		defer validate.Free()
+		settings = append(
+			settings,
+			prompt.WithValidate(func(value string) error {
+				return validateText(ctx, user, validate, value)
+			}),
+		)
+	}
+
+	result, err := showPrompt(ctx, user, prompt.NewText, settings...)
+	if err != nil {
+		return nil, err
+	}
+
+	if value, ok := result.(string); ok {
+		return &value, nil
+	}
+
+	return nil, nil
+}
+
+// validateText calls the :validate function provided to (input/text). It
+// rejects `value` with a message if the function returns a string, or with
+// a generic one if it returns false.
+func validateText(
+	ctx context.Context,
+	user interface{},
+	validate *janet.Function,
+	value string,
+) error {
+	result, err := validate.CallResult(ctx, user, value)
+	if err != nil {
+		return err
+	}
+	defer result.Free()
+
+	var message string
+	if err := result.Unmarshal(&message); err == nil {
+		return errors.New(message)
+	}
+
+	var ok bool
+	if err := result.Unmarshal(&ok); err == nil && !ok {
+		return fmt.Errorf("invalid value")
+	}
+
+	return nil
+}
+
+func (i *InputModule) Confirm(
+	ctx context.Context,
+	user interface{},
+	text string,
+) (bool, error) {
+	result, err := showPrompt(
+		ctx,
+		user,
+		prompt.NewConfirm,
+		prompt.WithPrompt(text),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	confirmed, _ := result.(bool)
+	return confirmed, nil
+}
+
+func (i *InputModule) Key(
+	ctx context.Context,
+	user interface{},
+	text string,
+) (*string, error) {
+	result, err := showPrompt(
+		ctx,
+		user,
+		prompt.NewKey,
+		prompt.WithPrompt(text),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if key, ok := result.(string); ok {
+		return &key, nil
+	}
+
+	return nil, nil
+}
+
+// commandInput describes a command whose standard output will be read
+// into the fuzzy finder, one option per line.
+type commandInput struct {
+	Command string
+	Args    *[]string
+	Cwd     *string
+}
+
+// streamCommand starts the command described by `input` and streams each
+// line it writes to stdout as an option. The command is killed when `ctx`
+// is cancelled.
+func streamCommand(
+	ctx context.Context,
+	input commandInput,
+) (<-chan fuzzy.Option, error) {
+	var args []string
+	if input.Args != nil {
+		args = *input.Args
+	}
+
+	command := exec.CommandContext(ctx, input.Command, args...)
+	if input.Cwd != nil {
+		command.Dir = *input.Cwd
+	}
+
+	stdout, err := command.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	err = command.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	stream := make(chan fuzzy.Option)
+	go func() {
+		defer close(stream)
+		defer command.Wait()
+
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			line := scanner.Text()
+			select {
+			case stream <- fuzzy.NewOption(line, line):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return stream, nil
+}
+
+// streamFiber streams each value yielded by a Janet generator as an
+// option.
+func streamFiber(
+	ctx context.Context,
+	fiber *janet.Fiber,
+) <-chan fuzzy.Option {
+	stream := make(chan fuzzy.Option)
+	go func() {
+		defer close(stream)
+		defer fiber.Free()
+
+		for {
+			value, err := fiber.Resume(ctx)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to resume generator")
+				return
+			}
+
+			if value == nil {
+				return
+			}
+
+			option, err := fuzzy.UnmarshalOption(value)
+			value.Free()
+			if err != nil {
+				log.Error().Err(err).Msg("generator yielded invalid option")
+				return
+			}
+
+			select {
+			case stream <- option:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return stream
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/cfoust/cy/pkg/bind"
 	"github.com/cfoust/cy/pkg/bind/trie"
@@ -12,6 +13,7 @@ import (
 type KeyModule struct {
 	Tree        *tree.Tree
 	ReplayBinds *bind.BindScope
+	Tables      *bind.Tables
 }
 
 var (
@@ -84,21 +86,52 @@ func getKeySequence(value *janet.Value) (result []interface{}, err error) {
 	return
 }
 
-func (k *KeyModule) Bind(target *janet.Value, sequence *janet.Value, callback *janet.Function) error {
+// resolveScope gets the bind scope referred to by `target`, which is either
+// :root, :replay, the NodeID of a group, or the name of a key table.
+func (k *KeyModule) resolveScope(target *janet.Value) (*bind.BindScope, error) {
+	group, err := k.resolveGroup(target)
+	if err == nil {
+		return group.Binds(), nil
+	}
+
+	replayErr := target.Unmarshal(&KEYWORD_REPLAY)
+	if replayErr == nil {
+		return k.ReplayBinds, nil
+	}
+
+	var name janet.Keyword
+	tableErr := target.Unmarshal(&name)
+	if tableErr != nil {
+		return nil, fmt.Errorf("target must be one of :root, :replay, a key table, or node ID")
+	}
+
+	table, ok := k.Tables.Get(string(name))
+	if !ok {
+		return nil, fmt.Errorf("key table :%s does not exist", name)
+	}
+
+	return table, nil
+}
+
+type BindParams struct {
+	Description string
+	Repeat      bool
+}
+
+func (k *KeyModule) Bind(
+	target *janet.Value,
+	sequence *janet.Value,
+	callback *janet.Function,
+	named *janet.Named[BindParams],
+) error {
 	defer target.Free()
 	defer sequence.Free()
 
-	var scope *bind.BindScope
-	group, err := k.resolveGroup(target)
-	if err == nil {
-		scope = group.Binds()
-	} else {
-		replayErr := target.Unmarshal(&KEYWORD_REPLAY)
-		if replayErr != nil {
-			return fmt.Errorf("target must be one of :root, :replay, or node ID")
-		}
+	params := named.Values()
 
-		scope = k.ReplayBinds
+	scope, err := k.resolveScope(target)
+	if err != nil {
+		return err
 	}
 
 	translated, err := getKeySequence(sequence)
@@ -109,9 +142,166 @@ func (k *KeyModule) Bind(target *janet.Value, sequence *janet.Value, callback *j
 	scope.Set(
 		translated,
 		bind.Action{
-			Callback: callback,
+			Description: params.Description,
+			Callback:    callback,
+			Repeat:      params.Repeat,
 		},
 	)
 
 	return nil
 }
+
+func (k *KeyModule) Unbind(target *janet.Value, sequence *janet.Value) error {
+	defer target.Free()
+	defer sequence.Free()
+
+	scope, err := k.resolveScope(target)
+	if err != nil {
+		return err
+	}
+
+	translated, err := getKeySequence(sequence)
+	if err != nil {
+		return err
+	}
+
+	if !scope.Remove(translated) {
+		return fmt.Errorf("no bindings found for sequence")
+	}
+
+	return nil
+}
+
+type Binding struct {
+	// Either :root, :replay or the NodeID of a group
+	Scope       interface{}
+	Sequence    []interface{}
+	Description string
+	Function    *janet.Value
+}
+
+// getBindings gets all of the bindings in `scope`, sorted by their
+// sequences.
+func getBindings(scope *bind.BindScope, target interface{}) (bindings []Binding) {
+	scope.Walk(func(sequence []interface{}, action bind.Action) {
+		binding := Binding{
+			Scope:       target,
+			Description: action.Description,
+		}
+
+		if action.Callback != nil {
+			binding.Function = action.Callback.Value
+		}
+
+		for _, step := range sequence {
+			switch step := step.(type) {
+			case string:
+				binding.Sequence = append(binding.Sequence, step)
+			case *trie.Regex:
+				binding.Sequence = append(
+					binding.Sequence,
+					regexKey{
+						Type:    KEYWORD_RE,
+						Pattern: step.Pattern,
+					},
+				)
+			}
+		}
+
+		bindings = append(bindings, binding)
+	})
+
+	sort.SliceStable(bindings, func(i, j int) bool {
+		return fmt.Sprint(bindings[i].Sequence...) < fmt.Sprint(bindings[j].Sequence...)
+	})
+
+	return
+}
+
+// List returns interface{} because Binding contains interface{} fields,
+// which the Janet interop does not accept in return types.
+func (k *KeyModule) List() interface{} {
+	var bindings []Binding
+	bindings = append(
+		bindings,
+		getBindings(k.Tree.Root().Binds(), KEYWORD_ROOT)...,
+	)
+
+	groups := k.Tree.Root().Children()
+	for len(groups) > 0 {
+		node := groups[0]
+		groups = groups[1:]
+
+		group, ok := node.(*tree.Group)
+		if !ok {
+			continue
+		}
+
+		bindings = append(
+			bindings,
+			getBindings(group.Binds(), group.Id())...,
+		)
+		groups = append(groups, group.Children()...)
+	}
+
+	bindings = append(
+		bindings,
+		getBindings(k.ReplayBinds, KEYWORD_REPLAY)...,
+	)
+
+	for _, name := range k.Tables.Names() {
+		table, ok := k.Tables.Get(name)
+		if !ok {
+			continue
+		}
+
+		bindings = append(
+			bindings,
+			getBindings(table, janet.Keyword(name))...,
+		)
+	}
+
+	return bindings
+}
+
+func (k *KeyModule) NewTable(name janet.Keyword) error {
+	if name == KEYWORD_ROOT || name == KEYWORD_REPLAY {
+		return fmt.Errorf("key table cannot be named :%s", name)
+	}
+
+	k.Tables.Create(string(name))
+	return nil
+}
+
+func (k *KeyModule) EnterTable(user interface{}, name janet.Keyword) error {
+	client, ok := user.(Client)
+	if !ok {
+		return fmt.Errorf("missing client context")
+	}
+
+	return client.SetKeyTable(string(name))
+}
+
+func (k *KeyModule) ExitTable(user interface{}) error {
+	client, ok := user.(Client)
+	if !ok {
+		return fmt.Errorf("missing client context")
+	}
+
+	return client.SetKeyTable("")
+}
+
+func (k *KeyModule) CurrentTable(user interface{}) (*janet.Keyword, error) {
+	client, ok := user.(Client)
+	if !ok {
+		return nil, fmt.Errorf("missing client context")
+	}
+
+	table := client.KeyTable()
+	if len(table) == 0 {
+		return nil, nil
+	}
+
+	keyword := janet.Keyword(table)
+	return &keyword, nil
+}
@@ -14,4 +14,10 @@ type Client interface {
 	OuterLayers() *screen.Layers
 	Margins() *screen.Margins
 	Frame() *frames.Framer
+	// KeyTable returns the name of the key table the client is in, or an
+	// empty string if it is not in one.
+	KeyTable() string
+	// SetKeyTable switches the client into the key table called `name`.
+	// An empty `name` switches the client out of its current key table.
+	SetKeyTable(name string) error
 }
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cfoust/cy/pkg/janet"
+	"github.com/cfoust/cy/pkg/mux"
+	"github.com/cfoust/cy/pkg/mux/screen"
+	"github.com/cfoust/cy/pkg/mux/screen/replayable"
+	"github.com/cfoust/cy/pkg/mux/screen/tree"
+	"github.com/cfoust/cy/pkg/taro"
+)
+
+type PaneModule struct {
+	Tree *tree.Tree
+}
+
+func (p *PaneModule) Attach(context interface{}, id tree.NodeID) error {
+	client, ok := context.(Client)
+	if !ok {
+		return fmt.Errorf("missing client context")
+	}
+
+	node, ok := p.Tree.NodeById(id)
+	if !ok {
+		return fmt.Errorf("node not found: %d", id)
+	}
+
+	return client.Attach(node)
+}
+
+func (p *PaneModule) Current(context interface{}) *tree.NodeID {
+	client, ok := context.(Client)
+	if !ok {
+		return nil
+	}
+
+	node := client.Node()
+	if node == nil {
+		return nil
+	}
+
+	id := node.Id()
+	return &id
+}
+
+// getTerminal gets the terminal underlying the pane with the given id.
+func getTerminal(t *tree.Tree, id tree.NodeID) (*screen.Terminal, error) {
+	pane, ok := t.PaneById(id)
+	if !ok {
+		return nil, fmt.Errorf("pane not found: %d", id)
+	}
+
+	var terminal mux.Screen = pane.Screen()
+	if r, ok := terminal.(*replayable.Replayable); ok {
+		terminal = r.Screen()
+	}
+
+	if terminal, ok := terminal.(*screen.Terminal); ok {
+		return terminal, nil
+	}
+
+	return nil, fmt.Errorf("pane %d was not a terminal", id)
+}
+
+func (p *PaneModule) ClearHistory(id tree.NodeID) error {
+	terminal, err := getTerminal(p.Tree, id)
+	if err != nil {
+		return err
+	}
+
+	terminal.ClearHistory()
+	return nil
+}
+
+func (p *PaneModule) SendKeys(id tree.NodeID, keys []string) error {
+	terminal, err := getTerminal(p.Tree, id)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range taro.KeysToMsg(keys...) {
+		terminal.Send(key)
+	}
+
+	return nil
+}
+
+type SendTextParams struct {
+	Paste bool
+}
+
+func (p *PaneModule) SendText(
+	id tree.NodeID,
+	text string,
+	params *janet.Named[SendTextParams],
+) error {
+	terminal, err := getTerminal(p.Tree, id)
+	if err != nil {
+		return err
+	}
+
+	values := params.Values()
+	return terminal.SendText(text, values.Paste)
+}
+
+type CaptureParams struct {
+	History int
+	ANSI    bool
+}
+
+func (p *PaneModule) Capture(
+	id tree.NodeID,
+	params *janet.Named[CaptureParams],
+) (string, error) {
+	terminal, err := getTerminal(p.Tree, id)
+	if err != nil {
+		return "", err
+	}
+
+	values := params.Values()
+	lines := make([]string, 0)
+	for _, line := range terminal.Capture(values.History) {
+		if values.ANSI {
+			lines = append(lines, line.ANSI())
+		} else {
+			lines = append(lines, line.Text())
+		}
+	}
+
+	// Blank lines at the bottom of the screen are not interesting
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
@@ -59,39 +59,6 @@ func (g *GroupModule) Leaves(parentId tree.NodeID) ([]tree.NodeID, error) {
 	return nodes, nil
 }
 
-type PaneModule struct {
-	Tree *tree.Tree
-}
-
-func (p *PaneModule) Attach(context interface{}, id tree.NodeID) error {
-	client, ok := context.(Client)
-	if !ok {
-		return fmt.Errorf("missing client context")
-	}
-
-	node, ok := p.Tree.NodeById(id)
-	if !ok {
-		return fmt.Errorf("node not found: %d", id)
-	}
-
-	return client.Attach(node)
-}
-
-func (p *PaneModule) Current(context interface{}) *tree.NodeID {
-	client, ok := context.(Client)
-	if !ok {
-		return nil
-	}
-
-	node := client.Node()
-	if node == nil {
-		return nil
-	}
-
-	id := node.Id()
-	return &id
-}
-
 type TreeModule struct {
 	Tree *tree.Tree
 }
@@ -9,16 +9,20 @@ import (
 	"github.com/cfoust/cy/pkg/bind"
 	"github.com/cfoust/cy/pkg/cy/api"
 	cyParams "github.com/cfoust/cy/pkg/cy/params"
+	"github.com/cfoust/cy/pkg/emu"
 	"github.com/cfoust/cy/pkg/frames"
 	"github.com/cfoust/cy/pkg/geom"
 	P "github.com/cfoust/cy/pkg/io/protocol"
 	"github.com/cfoust/cy/pkg/io/ws"
 	"github.com/cfoust/cy/pkg/janet"
 	"github.com/cfoust/cy/pkg/mux/screen"
+	"github.com/cfoust/cy/pkg/mux/screen/replayable"
 	"github.com/cfoust/cy/pkg/mux/screen/server"
 	"github.com/cfoust/cy/pkg/mux/screen/splash"
+	"github.com/cfoust/cy/pkg/mux/screen/status"
 	"github.com/cfoust/cy/pkg/mux/screen/toasts"
 	"github.com/cfoust/cy/pkg/mux/screen/tree"
+	"github.com/cfoust/cy/pkg/mux/screen/whichkey"
 	"github.com/cfoust/cy/pkg/mux/stream/renderer"
 	"github.com/cfoust/cy/pkg/params"
 	"github.com/cfoust/cy/pkg/taro"
@@ -33,6 +37,11 @@ type Connection = ws.Client[P.Message]
 
 type ClientID = int32
 
+// clientKeyFlags are the kitty keyboard protocol flags we request from
+// client terminals that support it. Keys are translated into whatever
+// encoding each pane expects.
+const clientKeyFlags = emu.KeyDisambiguate | emu.KeyReportEvents | emu.KeyReportAlternates
+
 type Client struct {
 	deadlock.RWMutex
 	util.Lifetime
@@ -50,6 +59,15 @@ type Client struct {
 
 	node  tree.Node
 	binds *bind.Engine[bind.Action]
+	// the name of the key table the client has switched into, if any
+	keyTable string
+
+	// the register the client is recording a macro into, if any, and
+	// the keys recorded so far
+	macroRegister string
+	macroKeys     []string
+	// the key sequence that triggered the most recent binding
+	lastSequence []string
 
 	// the text the client has copied
 	buffer string
@@ -60,6 +78,8 @@ type Client struct {
 	muxClient *server.Client
 	toast     *ToastLogger
 	toaster   *taro.Program
+	toasts    *toasts.Toaster
+	whichKey  *taro.Program
 	margins   *screen.Margins
 	frame     *frames.Framer
 	// Layers inside of the margins
@@ -68,7 +88,10 @@ type Client struct {
 	innerLayers *screen.Layers
 	// Layers outside of the margins
 	outerLayers *screen.Layers
-	renderer    *renderer.Renderer
+	// The status line, which is drawn around all of the other layers
+	status        *status.Status
+	statusUpdates chan struct{}
+	renderer      *renderer.Renderer
 
 	// history is an array of all of the panes this client has attached to
 	history []tree.NodeID
@@ -88,6 +111,8 @@ func (c *Cy) addClient(conn Connection) *Client {
 		conn:     conn,
 		params:   params.New(),
 		binds:    bind.NewEngine[bind.Action](),
+
+		statusUpdates: make(chan struct{}, 1),
 	}
 	c.clients = append(c.clients, client)
 	c.Unlock()
@@ -127,11 +152,26 @@ func (c *Client) runAction(event bind.BindEvent) {
 		args = append(args, arg)
 	}
 
-	err := event.Action.Callback.CallContext(
-		c.Ctx(),
-		c,
-		args...,
-	)
+	// If the user typed a count, we pass it to callbacks that can accept
+	// another argument and otherwise call them that many times
+	times := 1
+	if event.Count > 0 {
+		_, max := event.Action.Callback.Arity()
+		if max == -1 || max > len(args) {
+			args = append(args, event.Count)
+		} else {
+			times = event.Count
+		}
+	}
+
+	var err error
+	for i := 0; i < times && err == nil; i++ {
+		err = event.Action.Callback.CallContext(
+			c.Ctx(),
+			c,
+			args...,
+		)
+	}
 	if err == nil || err == context.Canceled {
 		return
 	}
@@ -162,13 +202,38 @@ func (c *Client) pollEvents() {
 			return
 		case event := <-c.binds.Recv():
 			if bind, ok := event.(bind.BindEvent); ok {
+				c.Lock()
+				c.lastSequence = bind.Sequence
+				c.Unlock()
+
 				go c.runAction(bind)
 				continue
 			}
 
+			if partial, ok := event.(bind.PartialEvent[bind.Action]); ok {
+				c.showWhichKey(partial)
+				continue
+			}
+
+			// The client's terminal supports the kitty keyboard
+			// protocol, so we enable it
+			if _, ok := event.(taro.KeyFlagsMsg); ok {
+				c.output([]byte(taro.PushKeyFlags(clientKeyFlags)))
+				continue
+			}
+
+			if mouse, ok := event.(taro.MouseMsg); ok {
+				// Clicking on a toast dismisses it
+				if c.dismissToast(mouse) {
+					continue
+				}
+
+				c.scrollIntoReplay(mouse)
+			}
+
 			// We only consider key presses to be an interaction
 			// We don't want mouse motion to trigger this
-			if _, ok := event.(taro.KeyMsg); ok {
+			if key, ok := event.(taro.KeyMsg); ok && key.Event != taro.KeyEventRelease {
 				c.interact(c.cy.writes)
 			}
 
@@ -177,6 +242,59 @@ func (c *Client) pollEvents() {
 	}
 }
 
+// dismissToast dismisses the toast the user clicked on, if any, and reports
+// whether it did so.
+func (c *Client) dismissToast(mouse taro.MouseMsg) bool {
+	if mouse.Type != taro.MousePress || !mouse.Down || mouse.Button != taro.MouseLeft {
+		return false
+	}
+
+	// Toasts are positioned relative to the space left over by the
+	// status line
+	position := mouse.Vec2.Sub(c.status.Inner().Position)
+	if !c.toasts.Contains(position) {
+		return false
+	}
+
+	c.toaster.Send(toasts.Dismiss{Position: position})
+	return true
+}
+
+// scrollIntoReplay enters replay mode when the user scrolls up in a pane
+// whose process does not handle scrolling itself, just like tmux. The
+// event is then sent on to replay mode as usual. Since this only happens
+// for events that did not match a binding, binding "wheel-up" overrides it.
+func (c *Client) scrollIntoReplay(mouse taro.MouseMsg) {
+	if key, ok := taro.MouseEvent(mouse).Key(); !ok || key != "wheel-up" {
+		return
+	}
+
+	enabled, _ := c.params.Get(cyParams.ParamScrollReplay)
+	if value, ok := enabled.(bool); !ok || !value {
+		return
+	}
+
+	// Something else, such as the fuzzy finder, is being shown over
+	// the pane
+	if c.outerLayers.Interactive() != c.margins || c.innerLayers.Interactive() != c.muxClient {
+		return
+	}
+
+	pane, ok := c.Node().(*tree.Pane)
+	if !ok {
+		return
+	}
+
+	r, ok := pane.Screen().(*replayable.Replayable)
+	if !ok || r.IsReplaying() {
+		return
+	}
+
+	if terminal, ok := r.Screen().(*screen.Terminal); ok && !terminal.WantsScroll() {
+		r.EnterReplay()
+	}
+}
+
 func (c *Cy) pollClient(ctx context.Context, client *Client) {
 	conn := client.conn
 	events := conn.Receive()
@@ -196,6 +314,15 @@ func (c *Cy) pollClient(ctx context.Context, client *Client) {
 		var err error
 		if handshake, ok := message.Contents.(*P.HandshakeMessage); ok {
 			err = client.initialize(handshake)
+		} else if request, ok := message.Contents.(*P.PickMessage); ok {
+			// `cy pick` only borrows another client's screen and
+			// never becomes a client itself
+			c.removeClient(client)
+			err = c.pick(conn, request, events)
+			if err != nil && err != context.Canceled {
+				client.closeError(err)
+			}
+			return
 		} else if !more {
 			err = fmt.Errorf("closed by remote")
 		} else {
@@ -219,12 +346,20 @@ func (c *Cy) pollClient(ctx context.Context, client *Client) {
 		return
 	}
 
+	// Terminals that support the kitty keyboard protocol will reply to
+	// this query, which is handled in pollEvents
+	client.output([]byte(taro.QueryKeyFlags))
+
 	c.sendQueuedToasts()
 
 	c.broadcastToast(client, toasts.Toast{
 		Message: "a client joined the server",
 	})
 
+	// We wait until the client has been attached to a pane (rather than
+	// doing this in addClient) so that hooks can act on it
+	c.runHooks(client, HookClientAttach)
+
 	for {
 		select {
 		case <-conn.Ctx().Done():
@@ -245,6 +380,7 @@ func (c *Cy) pollClient(ctx context.Context, client *Client) {
 
 			case P.MessageTypeInput:
 				msg := packet.Contents.(*P.InputMessage)
+				client.recordInput(msg.Data)
 				client.binds.Input(msg.Data)
 			}
 		}
@@ -273,6 +409,11 @@ func (c *Cy) removeClient(client *Client) {
 	}
 	c.clients = newClients
 	c.Unlock()
+
+	// Clients only get an ID once they've completed the handshake
+	if client.id != 0 {
+		c.runHooks(nil, HookClientDetach)
+	}
 }
 
 func (c *Client) output(data []byte) error {
@@ -359,7 +500,15 @@ func (c *Client) initialize(handshake *P.HandshakeMessage) error {
 		screen.WithInteractive,
 	)
 
-	c.toaster = toasts.New(c.Ctx())
+	c.whichKey = whichkey.New(c.Ctx())
+	c.outerLayers.NewLayer(
+		c.Ctx(),
+		c.whichKey,
+		screen.PositionTop,
+	)
+
+	c.toasts = toasts.NewToaster()
+	c.toaster = taro.New(c.Ctx(), c.toasts)
 	c.toast = NewToastLogger(c.sendToast)
 	c.outerLayers.NewLayer(
 		c.Ctx(),
@@ -367,11 +516,13 @@ func (c *Client) initialize(handshake *P.HandshakeMessage) error {
 		screen.PositionTop,
 	)
 
+	c.status = status.New(c.Ctx(), c.outerLayers)
+
 	c.renderer = renderer.NewRenderer(
 		c.Ctx(),
 		info,
 		handshake.Size,
-		c.outerLayers,
+		c.status,
 	)
 
 	if isClientSSH {
@@ -382,6 +533,7 @@ func (c *Client) initialize(handshake *P.HandshakeMessage) error {
 	}
 
 	go c.pollRender()
+	go c.pollStatus()
 
 	return nil
 }
@@ -389,16 +541,8 @@ func (c *Client) initialize(handshake *P.HandshakeMessage) error {
 // findNewPane looks for a pane that the client can attach to or creates a new
 // one if none are suitable.
 func (c *Client) findNewPane() error {
-	c.RLock()
-	history := c.history
-	c.RUnlock()
-
 	// First look back in history
-	for i := len(history) - 2; i >= 0; i-- {
-		node, ok := c.cy.tree.NodeById(history[i])
-		if !ok {
-			continue
-		}
+	if node, ok := c.previousNode(); ok {
 		return c.Attach(node)
 	}
 
@@ -444,16 +588,105 @@ func (c *Client) Attach(node tree.Node) error {
 	c.Unlock()
 
 	c.interact(c.cy.visits)
+	c.cy.countVisit(node.Id())
+	c.cy.clearAlerts(node.Id())
+	c.cy.runHooks(c, HookPaneAttach, node.Id())
+
+	c.updateScopes()
+	c.params.SetParent(node.Params())
+	c.configureBinds()
+	c.refreshStatus()
+
+	return nil
+}
+
+// updateScopes sets the scopes of the client's binding engine based on the
+// node it is attached to and the key table it is in. Key tables take
+// precedence over the bindings of every node.
+func (c *Client) updateScopes() {
+	c.RLock()
+	node := c.node
+	keyTable := c.keyTable
+	c.RUnlock()
 
-	// Update bindings
 	scopes := make([]*bind.BindScope, 0)
-	for _, pathNode := range path {
-		scopes = append(scopes, pathNode.Binds())
+	if node != nil {
+		for _, pathNode := range c.cy.tree.PathTo(node) {
+			scopes = append(scopes, pathNode.Binds())
+		}
+	}
+
+	if table, ok := c.cy.keyTables.Get(keyTable); ok && len(keyTable) > 0 {
+		scopes = append(scopes, table)
 	}
 
 	c.binds.SetScopes(scopes...)
-	c.params.SetParent(node.Params())
+}
+
+// getMilliseconds reads a parameter representing a duration in
+// milliseconds.
+func getMilliseconds(p *params.Parameters, key string) (time.Duration, bool) {
+	value, ok := p.Get(key)
+	if !ok {
+		return 0, false
+	}
+
+	ms, ok := value.(int)
+	if !ok || ms < 0 {
+		return 0, false
+	}
+
+	return time.Duration(ms) * time.Millisecond, true
+}
+
+// configureBinds applies the parameters of every client to its binding
+// engine.
+func (c *Cy) configureBinds() {
+	c.RLock()
+	clients := c.clients
+	c.RUnlock()
+
+	for _, client := range clients {
+		client.configureBinds()
+	}
+}
+
+// configureBinds applies the client's parameters to its binding engine.
+func (c *Client) configureBinds() {
+	timeout, ok := getMilliseconds(c.params, cyParams.ParamKeyTimeout)
+	if !ok || timeout == 0 {
+		timeout = bind.DEFAULT_TIMEOUT
+	}
+	c.binds.SetTimeout(timeout)
+
+	repeatTimeout, ok := getMilliseconds(c.params, cyParams.ParamRepeatTimeout)
+	if !ok {
+		repeatTimeout = bind.DEFAULT_REPEAT_TIMEOUT
+	}
+	c.binds.SetRepeatTimeout(repeatTimeout)
+
+	counts, _ := c.params.Get(cyParams.ParamKeyCounts)
+	enabled, _ := counts.(bool)
+	c.binds.SetCounts(enabled)
+}
+
+func (c *Client) KeyTable() string {
+	c.RLock()
+	defer c.RUnlock()
+	return c.keyTable
+}
+
+func (c *Client) SetKeyTable(name string) error {
+	if _, ok := c.cy.keyTables.Get(name); !ok && len(name) > 0 {
+		return fmt.Errorf("key table %s does not exist", name)
+	}
+
+	c.Lock()
+	c.keyTable = name
+	c.Unlock()
 
+	c.updateScopes()
+	c.refreshStatus()
 	return nil
 }
 
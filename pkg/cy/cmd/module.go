@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/cfoust/cy/pkg/bind"
+	"github.com/cfoust/cy/pkg/emu"
 	"github.com/cfoust/cy/pkg/geom"
 	"github.com/cfoust/cy/pkg/mux/screen"
 	"github.com/cfoust/cy/pkg/mux/screen/replayable"
@@ -16,6 +17,7 @@ func New(
 	options stream.CmdOptions,
 	dataDir string,
 	replayBinds *bind.BindScope,
+	terminalOptions ...emu.TerminalOption,
 ) (*replayable.Replayable, error) {
 	cmd, err := stream.NewCmd(
 		ctx,
@@ -26,6 +28,8 @@ func New(
 		return nil, err
 	}
 
+	exits := cmd.SubscribeExits(ctx)
+
 	var borgPath string
 	if len(dataDir) > 0 {
 		borgPath, err = sessions.GetFilename(dataDir, options.Directory)
@@ -39,7 +43,12 @@ func New(
 		return nil, err
 	}
 
-	terminal := screen.NewTerminal(ctx, recorder, geom.DEFAULT_SIZE)
+	terminal := screen.NewTerminal(
+		ctx,
+		recorder,
+		geom.DEFAULT_SIZE,
+		terminalOptions...,
+	)
 	replayable := replayable.New(
 		ctx,
 		terminal,
@@ -47,5 +56,18 @@ func New(
 		recorder,
 		replayBinds,
 	)
+
+	// Pass exit events up so that they reach the tree
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-exits.Recv():
+				replayable.Publish(event)
+			}
+		}
+	}()
+
 	return replayable, nil
 }
@@ -2,15 +2,23 @@ package cy
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"syscall"
 	"testing"
 	"time"
 
+	"github.com/cfoust/cy/pkg/cy/api"
 	"github.com/cfoust/cy/pkg/cy/cmd"
 	"github.com/cfoust/cy/pkg/geom"
 	P "github.com/cfoust/cy/pkg/io/protocol"
 	"github.com/cfoust/cy/pkg/io/ws"
+	"github.com/cfoust/cy/pkg/janet"
+	"github.com/cfoust/cy/pkg/mux/screen"
+	"github.com/cfoust/cy/pkg/mux/screen/replayable"
+	"github.com/cfoust/cy/pkg/mux/screen/tree"
 	"github.com/cfoust/cy/pkg/mux/stream"
 	"github.com/cfoust/cy/pkg/util"
 
@@ -68,6 +76,30 @@ func (t *TestServer) Release() {
 	t.Cancel()
 }
 
+// getLines returns the text of each line on the screen of the pane `id`.
+func (t *TestServer) getLines(id tree.NodeID) []string {
+	pane, ok := t.cy.tree.PaneById(id)
+	if !ok {
+		return nil
+	}
+
+	r, ok := pane.Screen().(*replayable.Replayable)
+	if !ok {
+		return nil
+	}
+
+	terminal, ok := r.Screen().(*screen.Terminal)
+	if !ok {
+		return nil
+	}
+
+	var lines []string
+	for _, line := range terminal.Capture(0) {
+		lines = append(lines, line.Text())
+	}
+	return lines
+}
+
 func setupServer(t *testing.T) *TestServer {
 	dir, err := os.MkdirTemp("", "example")
 	require.NoError(t, err)
@@ -217,3 +249,863 @@ func TestPaneKill(t *testing.T) {
 	leaves = server.cy.tree.Leaves()
 	require.Equal(t, leaves[1].Id(), client.Node().Id())
 }
+
+func TestHooks(t *testing.T) {
+	server := setupServer(t)
+	defer server.Release()
+
+	cy := server.cy
+	a := cy.tree.Root().NewGroup()
+	b := cy.tree.Root().NewGroup()
+
+	require.NoError(t, cy.Execute(server.Ctx(), fmt.Sprintf(`
+(hook/add :node-rename (fn [node name]
+  (if (and (= node %d) (= name "foo"))
+    (tree/set-name %d "bar"))))
+`, a.Id(), b.Id())))
+
+	a.SetName("foo")
+	require.Eventually(t, func() bool {
+		return b.Name() == "bar"
+	}, time.Second, 10*time.Millisecond)
+
+	// Hooks run in the order their events occurred
+	c := cy.tree.Root().NewGroup()
+	c.SetName("-")
+	require.NoError(t, cy.Execute(server.Ctx(), fmt.Sprintf(`
+(hook/add :node-rename (fn [node name]
+  (if (= node %d)
+    (tree/set-name %d (string (tree/name %d) name)))))
+`, a.Id(), c.Id(), c.Id())))
+	for _, name := range []string{"a", "b", "c", "d"} {
+		a.SetName(name)
+	}
+	require.Eventually(t, func() bool {
+		return c.Name() == "-abcd"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestHookEvents(t *testing.T) {
+	server := setupServer(t)
+	defer server.Release()
+
+	cy := server.cy
+	results := cy.tree.Root().NewGroup()
+	getResults := func() string {
+		return strings.TrimPrefix(results.Name(), "-")
+	}
+
+	require.NoError(t, cy.Execute(server.Ctx(), fmt.Sprintf(`
+(defn record [value]
+  (tree/set-name %d (string (tree/name %d) value ";")))
+
+(hook/add :client-attach (fn [] (record "attach")))
+(hook/add :client-detach (fn [] (record "detach")))
+(hook/add :pane-title (fn [pane title] (record title)))
+# Close panes whose process exited with code 3
+(hook/add :pane-exit (fn [pane code]
+  (when (= code 3)
+    (record code)
+    (tree/kill pane))))
+`, results.Id(), results.Id())))
+	results.SetName("-")
+
+	conn, client, err := server.Standard()
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return getResults() == "attach;"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Events that occur before the pane is added to the tree are lost, so
+	// these commands wait a moment first
+	require.NoError(t, client.execute(`
+(cmd/new (tree/root) "" :command "sh" :args ["-c" "sleep 0.2; printf '\e]2;hello\x07'; sleep 10"])
+`))
+	require.Eventually(t, func() bool {
+		return getResults() == "attach;hello;"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	numChildren := len(cy.tree.Root().Children())
+	require.NoError(t, client.execute(`
+(cmd/new (tree/root) "" :command "sh" :args ["-c" "sleep 0.2; exit 3"])
+`))
+	require.Eventually(t, func() bool {
+		return getResults() == "attach;hello;3;"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// The hook killed the pane, so its process does not run again
+	require.Len(t, cy.tree.Root().Children(), numChildren)
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, "attach;hello;3;", getResults())
+
+	require.NoError(t, conn.Close())
+	require.Eventually(t, func() bool {
+		return getResults() == "attach;hello;3;detach;"
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestTimers(t *testing.T) {
+	server := setupServer(t)
+	defer server.Release()
+
+	cy := server.cy
+	group := cy.tree.Root().NewGroup()
+
+	require.NoError(t, cy.Execute(server.Ctx(), fmt.Sprintf(`
+(var count 0)
+(var timer nil)
+(set timer (cy/every 10 (fn []
+  (++ count)
+  (if (= count 3) (cy/cancel timer))
+  (tree/set-name %d (string count))
+  # errors should not stop the timer
+  (error "oops"))))
+`, group.Id())))
+
+	require.Eventually(t, func() bool {
+		return group.Name() == "3"
+	}, time.Second, 10*time.Millisecond)
+
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, "3", group.Name())
+}
+
+func TestKeyList(t *testing.T) {
+	server := setupServer(t)
+	defer server.Release()
+
+	cy := server.cy
+	group := cy.tree.Root().NewGroup()
+
+	require.NoError(t, cy.Execute(server.Ctx(), fmt.Sprintf(`
+(key/bind %d ["ctrl+b" "a"] (fn []) :description "first")
+(key/bind %d ["ctrl+b" [:re "[bc]"]] (fn [_]))
+(key/bind %d ["ctrl+c"] (fn []))
+
+(def binds (filter |(= ($ :scope) %d) (key/list)))
+(assert (= 3 (length binds)))
+(assert (deep= @["ctrl+b" "a"] ((binds 0) :sequence)))
+(assert (= "first" ((binds 0) :description)))
+(assert (deep= @["ctrl+b" [:re "[bc]"]] ((binds 1) :sequence)))
+
+# default bindings are described by their docstrings
+(assert (find |(and (= ($ :scope) :replay) (= ($ :description) "Quit replay mode.")) (key/list)))
+(assert (find |(and (= ($ :scope) :root) (= ($ :description) "create a new shell")) (key/list)))
+
+(key/unbind %d ["ctrl+b"])
+(assert (= 1 (length (filter |(= ($ :scope) %d) (key/list)))))
+`,
+		group.Id(),
+		group.Id(),
+		group.Id(),
+		group.Id(),
+		group.Id(),
+		group.Id(),
+	)))
+
+	require.Error(t, cy.Execute(server.Ctx(), fmt.Sprintf(
+		`(key/unbind %d ["ctrl+b"])`,
+		group.Id(),
+	)))
+}
+
+func TestKeyTables(t *testing.T) {
+	server := setupServer(t)
+	defer server.Release()
+
+	_, client, err := server.Standard()
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return len(client.binds.Scopes()) == 3
+	}, time.Second, 10*time.Millisecond)
+
+	require.Error(t, client.execute(`(key/enter-table :resize)`))
+	require.Error(t, client.execute(`(key/new-table :replay)`))
+
+	require.NoError(t, client.execute(`
+(key/new-table :resize)
+(key/bind :resize ["h"] (fn []))
+(key/bind :resize ["esc"] key/exit-table)
+(assert (nil? (key/current-table)))
+(key/enter-table :resize)
+(assert (= :resize (key/current-table)))
+(assert (= 2 (length (filter |(= ($ :scope) :resize) (key/list)))))
+`))
+	require.Equal(t, "resize", client.KeyTable())
+	require.Equal(t, 4, len(client.binds.Scopes()))
+
+	// The key table should survive attaching to another pane
+	require.NoError(t, client.execute(`(shell/attach)`))
+	require.Equal(t, 4, len(client.binds.Scopes()))
+
+	require.NoError(t, client.execute(`(key/exit-table)`))
+	require.Equal(t, "", client.KeyTable())
+	require.Equal(t, 3, len(client.binds.Scopes()))
+}
+
+func TestKeyCounts(t *testing.T) {
+	server := setupServer(t)
+	defer server.Release()
+
+	conn, client, err := server.Standard()
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return client.Node() != nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	group := server.cy.tree.Root().NewGroup()
+	require.NoError(t, client.execute(fmt.Sprintf(`
+(cy/set :key-counts true)
+(key/bind :root ["ctrl+b" "c"] (fn [&opt count] (tree/set-name %d (string count))))
+(var calls 0)
+(key/bind :root ["ctrl+b" "t"] (fn [] (++ calls) (tree/set-name %d (string calls))))
+`, group.Id(), group.Id())))
+
+	// Each key is sent separately, as it would be if a person typed it
+	for _, key := range []string{"1", "2", "\x02", "c"} {
+		require.NoError(t, conn.Send(P.InputMessage{Data: []byte(key)}))
+	}
+	require.Eventually(t, func() bool {
+		return group.Name() == "12"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Callbacks that do not accept a count are called repeatedly
+	for _, key := range []string{"3", "\x02", "t"} {
+		require.NoError(t, conn.Send(P.InputMessage{Data: []byte(key)}))
+	}
+	require.Eventually(t, func() bool {
+		return group.Name() == "3"
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestMacros(t *testing.T) {
+	server := setupServer(t)
+	defer server.Release()
+
+	conn, client, err := server.Standard()
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return client.Node() != nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, client.execute(`
+(key/bind :root ["ctrl+b" "s"] macro/stop)
+(key/bind :root ["ctrl+b" "k"]
+  (fn [] (macro/set "k" [;(macro/get "k") "k"])))
+(macro/record "a")
+`))
+	require.Error(t, client.execute(`(macro/record "b")`))
+
+	numCalls := func() int {
+		keys, _ := server.cy.macros.get("k")
+		return len(keys)
+	}
+
+	// Keys that trigger bindings are recorded, but the ones that stop
+	// recording are not
+	for _, key := range []string{"l", "\x02", "k", "\r", "\x02", "s"} {
+		require.NoError(t, conn.Send(P.InputMessage{Data: []byte(key)}))
+	}
+	require.Eventually(t, func() bool {
+		return client.MacroRegister() == ""
+	}, 2*time.Second, 10*time.Millisecond)
+	require.Equal(t, 1, numCalls())
+
+	recorded := []string{"l", "ctrl+b", "k", "enter"}
+	keys, ok := server.cy.macros.get("a")
+	require.True(t, ok)
+	require.Equal(t, recorded, keys)
+
+	// Registers are persisted to the data directory
+	macros := newMacroRegistry(server.cy.getDataDir)
+	keys, ok = macros.get("a")
+	require.True(t, ok)
+	require.Equal(t, recorded, keys)
+
+	// Playing the macro triggers its bindings again
+	require.NoError(t, client.execute(`(macro/play "a" :times 2)`))
+	require.Eventually(t, func() bool {
+		return numCalls() == 3
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Macros can also be written to any pane
+	group := server.cy.tree.Root().NewGroup()
+	require.NoError(t, client.execute(fmt.Sprintf(`
+(def pane (cmd/new %d "" :command "cat"))
+(macro/set "c" ["h" "i" "enter"])
+(macro/play "c" :pane pane :times 2)
+`, group.Id())))
+	require.Eventually(t, func() bool {
+		children := group.Children()
+		if len(children) != 1 {
+			return false
+		}
+		lines := server.getLines(children[0].Id())
+		return len(lines) > 3 && lines[0] == "hi" && lines[1] == "hi" &&
+			lines[2] == "hi" && lines[3] == "hi"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, client.execute(`
+(macro/set "a" [])
+(macro/set "c" [])
+(macro/set "k" [])
+`))
+	require.Error(t, client.execute(`(macro/play "a")`))
+	require.Empty(t, server.cy.macros.names())
+}
+
+func TestHistory(t *testing.T) {
+	server := setupServer(t)
+	defer server.Release()
+
+	_, client, err := server.Standard()
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return client.Node() != nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	history := &HistoryModule{cy: server.cy}
+	first := client.Node().Id()
+
+	previous, err := history.Previous(client)
+	require.NoError(t, err)
+	require.Nil(t, previous)
+
+	require.NoError(t, client.execute(`(pane/attach (shell/new))`))
+	second := client.Node().Id()
+	require.NotEqual(t, first, second)
+
+	previous, err = history.Previous(client)
+	require.NoError(t, err)
+	require.Equal(t, first, *previous)
+
+	// Visiting the first pane again makes it the most frecent
+	require.NoError(t, client.execute(fmt.Sprintf(`
+(pane/attach %d)
+(pane/attach (history/previous))
+(pane/attach (history/previous))
+`, first)))
+	require.Equal(t, first, client.Node().Id())
+	require.Eventually(t, func() bool {
+		recent := history.Recent()
+		return len(recent) == 2 && recent[0] == first
+	}, 2*time.Second, 10*time.Millisecond)
+	require.Equal(t, []tree.NodeID{first, second}, history.Frecent())
+	require.Greater(t, history.Frecency(first), history.Frecency(second))
+	require.Zero(t, history.Frecency(server.cy.tree.Root().Id()))
+}
+
+func TestPick(t *testing.T) {
+	server := setupServer(t)
+	defer server.Release()
+
+	conn, client, err := server.Standard()
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return client.Node() != nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	pane, ok := client.Node().(*tree.Pane)
+	require.True(t, ok)
+	r, ok := pane.Screen().(*replayable.Replayable)
+	require.True(t, ok)
+	cmd, ok := r.Stream().(*stream.Cmd)
+	require.True(t, ok)
+
+	var session int
+	require.Eventually(t, func() bool {
+		session, err = cmd.Pid()
+		return err == nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// `cy pick` run inside of the client's pane
+	pick, err := ws.Connect(server.Ctx(), P.Protocol, server.socketPath)
+	require.NoError(t, err)
+
+	numLayers := client.OuterLayers().NumLayers()
+	require.NoError(t, pick.Send(P.PickMessage{
+		Session: session,
+		Multi:   true,
+	}))
+	require.NoError(t, pick.Send(P.OptionsMessage{
+		Options: []string{"bar", "foo"},
+	}))
+	require.NoError(t, pick.Send(P.OptionsMessage{
+		Options: []string{"baz"},
+		Done:    true,
+	}))
+
+	isShown := func(text string) bool {
+		for _, line := range client.OuterLayers().State().Image {
+			if strings.Contains(line.String(), text) {
+				return true
+			}
+		}
+		return false
+	}
+
+	require.Eventually(t, func() bool {
+		return client.OuterLayers().NumLayers() > numLayers && isShown("baz")
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Choose every option that matches "ba"
+	require.NoError(t, conn.Send(P.InputMessage{Data: []byte("ba")}))
+	require.Eventually(t, func() bool {
+		return isShown("2/3")
+	}, 2*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, conn.Send(P.InputMessage{Data: []byte("\x1ba")}))
+	require.Eventually(t, func() bool {
+		return isShown("(2)")
+	}, 2*time.Second, 10*time.Millisecond)
+	require.NoError(t, conn.Send(P.InputMessage{Data: []byte("\r")}))
+
+	select {
+	case packet := <-pick.Receive():
+		require.NoError(t, packet.Error)
+		require.Equal(t, &P.ChosenMessage{
+			Options: []string{"bar", "baz"},
+		}, packet.Contents)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no options were chosen")
+	}
+}
+
+func TestInputText(t *testing.T) {
+	server := setupServer(t)
+	defer server.Release()
+
+	conn, client, err := server.Standard()
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return client.Node() != nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	group := server.cy.tree.Root().NewGroup()
+	require.NoError(t, client.execute(fmt.Sprintf(`
+(key/bind :root ["ctrl+b" "r"] (fn []
+  (def name (input/text "rename" :default "a" :validate (fn [value] (if (= value "ab") "taken"))))
+  (tree/set-name %d name)))
+(key/bind :root ["ctrl+b" "k"] (fn []
+  (tree/set-name %d (input/key "key"))))
+`, group.Id(), group.Id())))
+
+	// Types `trigger`, waits for the prompt it opens to appear, then
+	// types `keys`
+	prompt := func(trigger string, keys ...string) {
+		numLayers := client.OuterLayers().NumLayers()
+		require.NoError(t, conn.Send(P.InputMessage{Data: []byte(trigger)}))
+		require.Eventually(t, func() bool {
+			return client.OuterLayers().NumLayers() > numLayers
+		}, 2*time.Second, 10*time.Millisecond)
+
+		for _, key := range keys {
+			require.NoError(t, conn.Send(P.InputMessage{Data: []byte(key)}))
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+
+	// "ab" is rejected, so the user has to keep typing
+	prompt("\x02r", "b", "\r", "c", "\r")
+	require.Eventually(t, func() bool {
+		return group.Name() == "abc"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	prompt("\x02k", "\x07")
+	require.Eventually(t, func() bool {
+		return group.Name() == "ctrl+g"
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestStatusLine(t *testing.T) {
+	server := setupServer(t)
+	defer server.Release()
+
+	_, client, err := server.Standard()
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return client.Node() != nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	getLine := func(row int) string {
+		return client.status.State().Image[row].String()
+	}
+
+	// The status line is hidden by default
+	require.NoError(t, client.execute(`(status/refresh)`))
+	time.Sleep(100 * time.Millisecond)
+	require.False(t, client.status.Visible())
+
+	// The default render function shows the path to the pane
+	require.NoError(t, client.execute(`(status/toggle)`))
+	require.Eventually(t, func() bool {
+		return strings.HasPrefix(getLine(25), " /shells/")
+	}, 2*time.Second, 10*time.Millisecond)
+	require.Equal(t, 25, client.muxClient.Size().R)
+
+	require.NoError(t, client.execute(`
+(key/new-table :test)
+(cy/set :status-position "top")
+(status/set-render (fn [{:key-table table}]
+  (string "#[bold]" table "#[align=right]##")))
+(key/enter-table :test)
+`))
+	require.Eventually(t, func() bool {
+		return getLine(0) == "test"+strings.Repeat(" ", 75)+"#"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Errors are shown in the status line
+	require.NoError(t, client.execute(`(status/set-render (fn [&] "#[nope]"))`))
+	require.Eventually(t, func() bool {
+		return strings.HasPrefix(getLine(0), "error: unknown attribute: nope")
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestScrollReplay(t *testing.T) {
+	server := setupServer(t)
+	defer server.Release()
+
+	conn, client, err := server.Standard()
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return client.Node() != nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	pane, ok := client.Node().(*tree.Pane)
+	require.True(t, ok)
+	r, ok := pane.Screen().(*replayable.Replayable)
+	require.True(t, ok)
+
+	// Dismiss the splash screen
+	require.NoError(t, conn.Send(P.InputMessage{Data: []byte("x")}))
+	require.Eventually(t, func() bool {
+		return client.OuterLayers().Interactive() == client.margins
+	}, 2*time.Second, 10*time.Millisecond)
+
+	wheelUp := []byte("\x1b[M`*%")
+	require.NoError(t, client.execute(`(cy/set :scroll-replay false)`))
+	require.NoError(t, conn.Send(P.InputMessage{Data: wheelUp}))
+	time.Sleep(100 * time.Millisecond)
+	require.False(t, r.IsReplaying())
+
+	require.NoError(t, client.execute(`(cy/set :scroll-replay true)`))
+	require.NoError(t, conn.Send(P.InputMessage{Data: wheelUp}))
+	require.Eventually(t, func() bool {
+		return r.IsReplaying()
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestNewProjectRemote(t *testing.T) {
+	// Stand in for ssh by printing the arguments we get
+	bin := t.TempDir()
+	require.NoError(t, os.WriteFile(
+		filepath.Join(bin, "ssh"),
+		[]byte("#!/bin/sh\necho \"$@\"\n"),
+		0755,
+	))
+	t.Setenv("PATH", bin+":"+os.Getenv("PATH"))
+	t.Setenv("EDITOR", "vim")
+
+	server := setupServer(t)
+	defer server.Release()
+
+	_, client, err := server.Standard()
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return client.Node() != nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// A pane that reports a directory on another machine
+	require.NoError(t, client.execute(`
+(def pane (cmd/new (tree/root) "" :command "printf" :args ["\e]7;file://otherhost/remote/dir\x07"]))
+(pane/attach pane)
+`))
+	require.Eventually(t, func() bool {
+		host, err := (&api.Cmd{Tree: server.cy.tree}).Host(client.Node().Id())
+		return err == nil && host != nil && *host == "otherhost"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, client.execute(`(action/new-project)`))
+
+	getText := func(node tree.Node) string {
+		lines := server.getLines(node.Id())
+		require.NotEmpty(t, lines)
+		return lines[0]
+	}
+
+	editor := client.Node()
+	parent := (&api.TreeModule{Tree: server.cy.tree}).Parent(editor.Id())
+	require.NotNil(t, parent)
+	project, ok := server.cy.tree.GroupById(*parent)
+	require.True(t, ok)
+	children := project.Children()
+	require.Len(t, children, 2)
+
+	require.Eventually(t, func() bool {
+		return getText(children[0]) == "-t otherhost cd '/remote/dir' && exec vim" &&
+			getText(children[1]) == "-t otherhost cd '/remote/dir' && exec $SHELL -l"
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestCmdRun(t *testing.T) {
+	server := setupServer(t)
+	defer server.Release()
+
+	_, client, err := server.Standard()
+	require.NoError(t, err)
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, client.execute(fmt.Sprintf(`
+(def result (cmd/run ["sh" "-c" "echo out; echo err >&2; exit 3"]))
+(assert (= "out\n" (result :stdout)))
+(assert (= "err\n" (result :stderr)))
+(assert (= 3 (result :code)))
+
+(assert (= "%s\n" ((cmd/run ["pwd"] :dir "%s") :stdout)))
+
+(def [ok err] (protect (cmd/run ["nonexistent-command"])))
+(assert (not ok))
+`, dir, dir)))
+
+	// The timeout stops the command
+	start := time.Now()
+	require.NoError(t, client.execute(`
+(def [ok err] (protect (cmd/run ["sleep" "10"] :timeout 100)))
+(assert (not ok))
+(assert (string/find "was stopped" err))
+`))
+	require.Less(t, time.Since(start), 5*time.Second)
+
+	// Processes left in the background do not keep us waiting
+	start = time.Now()
+	require.NoError(t, client.execute(`
+(assert (= "done\n" ((cmd/run ["sh" "-c" "sleep 10 & echo done"]) :stdout)))
+`))
+	require.Less(t, time.Since(start), 5*time.Second)
+
+	// Output is limited
+	require.NoError(t, client.execute(`
+(def [ok err] (protect (cmd/run ["head" "-c" "20000000" "/dev/zero"])))
+(assert (not ok))
+(assert (string/find "bytes of output" err))
+`))
+
+	// Cancelling the caller stops the command
+	pidFile := filepath.Join(dir, "pid")
+	ctx, cancel := context.WithCancel(client.Ctx())
+	done := make(chan error)
+	go func() {
+		done <- server.cy.ExecuteCall(ctx, client, janet.Call{
+			Code: []byte(fmt.Sprintf(
+				`(cmd/run ["sh" "-c" "echo $$ > %s; exec sleep 10"])`,
+				pidFile,
+			)),
+			Options: janet.DEFAULT_CALL_OPTIONS,
+		})
+	}()
+
+	var pid int
+	require.Eventually(t, func() bool {
+		data, err := os.ReadFile(pidFile)
+		if err != nil {
+			return false
+		}
+		_, err = fmt.Sscanf(string(data), "%d", &pid)
+		return err == nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.Error(t, <-done)
+	require.Eventually(t, func() bool {
+		return syscall.Kill(pid, 0) != nil
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestPaneSend(t *testing.T) {
+	server := setupServer(t)
+	defer server.Release()
+
+	_, client, err := server.Standard()
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		// Escape sequences the program sends before reading its input
+		modes string
+		// What the program received
+		input string
+	}{
+		{"", "^[[A^[x^Ahi|a"},
+		// application cursor keys and bracketed paste
+		{`\e[?1h\e[?2004h`, "^[OA^[x^Ahi^[[200~|a^[[201~"},
+	} {
+		group := server.cy.tree.Root().NewGroup()
+		getLines := func() []string {
+			children := group.Children()
+			if len(children) != 1 {
+				return nil
+			}
+			return server.getLines(children[0].Id())
+		}
+
+		// cat -v shows us exactly what the pane receives
+		require.NoError(t, client.execute(fmt.Sprintf(`
+(cmd/new %d "" :command "sh" :args ["-c" "stty -icanon -echo; printf '%sready\n'; exec cat -v"])
+`, group.Id(), test.modes)))
+		require.Eventually(t, func() bool {
+			lines := getLines()
+			return len(lines) > 0 && lines[0] == "ready"
+		}, 2*time.Second, 10*time.Millisecond)
+
+		require.NoError(t, client.execute(fmt.Sprintf(`
+(def pane %d)
+(pane/send-keys pane ["up" "alt+x" "ctrl+a" "hi"])
+(pane/send-text pane "|a" :paste true)
+`, group.Children()[0].Id())))
+		require.Eventually(t, func() bool {
+			lines := getLines()
+			return len(lines) > 1 && lines[1] == test.input
+		}, 2*time.Second, 10*time.Millisecond, test.input)
+	}
+}
+
+func TestPaneCapture(t *testing.T) {
+	server := setupServer(t)
+	defer server.Release()
+
+	_, client, err := server.Standard()
+	require.NoError(t, err)
+
+	group := server.cy.tree.Root().NewGroup()
+	require.NoError(t, client.execute(fmt.Sprintf(`
+(cmd/new %d "" :command "sh" :args ["-c" "for i in $(seq 1 100); do echo line $i; done; printf '\\033[31mred\\033[0m\\n'; exec cat"])
+`, group.Id())))
+	require.Eventually(t, func() bool {
+		children := group.Children()
+		if len(children) != 1 {
+			return false
+		}
+		lines := server.getLines(children[0].Id())
+		for _, line := range lines {
+			if line == "red" {
+				return true
+			}
+		}
+		return false
+	}, 2*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, client.execute(fmt.Sprintf(`
+(def pane %d)
+(def plain (string/split "\n" (pane/capture pane)))
+(assert (= "red" (last plain)))
+(assert (not (string/find "\e" (string/join plain))))
+
+# the first line on the screen is "line N"
+(def first-line (scan-number (string/slice (first plain) 5)))
+
+(def history (string/split "\n" (pane/capture pane :history 3)))
+(assert (= (+ 3 (length plain)) (length history)))
+(assert (deep= plain (array/slice history 3)))
+(assert (= (string "line " (- first-line 3)) (first history)))
+
+(def ansi (string/split "\n" (pane/capture pane :ansi true)))
+(assert (= (length plain) (length ansi)))
+(assert (string/find "\e[" (last ansi)))
+(assert (string/find "red" (last ansi)))
+`, group.Children()[0].Id())))
+}
+
+func TestWhichKey(t *testing.T) {
+	server := setupServer(t)
+	defer server.Release()
+
+	conn, client, err := server.Standard()
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return client.Node() != nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	group := server.cy.tree.Root().NewGroup()
+	require.NoError(t, client.execute(fmt.Sprintf(`
+(key/bind :root ["ctrl+b" "d"] (fn []) :description "root-d")
+(key/bind :root ["ctrl+b" "e"] (fn [] (tree/set-name %d "e")) :description "name-e")
+(key/bind :root ["ctrl+b" [:re "[0-9]"]] (fn []) :description "digit")
+(key/bind %d ["ctrl+b" "d"] (fn []) :description "group-d")
+(pane/attach (cmd/new %d ""))
+`, group.Id(), group.Id(), group.Id())))
+	require.Eventually(t, func() bool {
+		children := group.Children()
+		return len(children) == 1 && client.Node().Id() == children[0].Id()
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Parameters are set on the client's pane, so we set them after it
+	// is attached
+	require.NoError(t, client.execute(`
+(cy/set :which-key true)
+(cy/set :which-key-delay 0)
+`))
+
+	isShown := func(text string) bool {
+		for _, line := range client.OuterLayers().State().Image {
+			if strings.Contains(line.String(), text) {
+				return true
+			}
+		}
+		return false
+	}
+
+	send := func(key string) {
+		require.NoError(t, conn.Send(P.InputMessage{Data: []byte(key)}))
+	}
+
+	// Dismiss the splash screen
+	send("x")
+	require.Eventually(t, func() bool {
+		return client.OuterLayers().Interactive() == client.margins
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Bindings in the group shadow the ones in :root
+	send("\x02")
+	require.Eventually(t, func() bool {
+		return isShown("group-d") && isShown("/[0-9]/") && isShown("digit")
+	}, 2*time.Second, 10*time.Millisecond)
+	require.False(t, isShown("root-d"))
+
+	// Completing the sequence hides the hints
+	send("e")
+	require.Eventually(t, func() bool {
+		return group.Name() == "e" && !isShown("name-e")
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// So does waiting too long to type the next key
+	send("\x02")
+	require.Eventually(t, func() bool {
+		return isShown("name-e")
+	}, 2*time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool {
+		return !isShown("name-e")
+	}, 3*time.Second, 10*time.Millisecond)
+
+	// Hints only appear after the delay, which is shorter than the
+	// default key timeout
+	require.NoError(t, client.execute(`(cy/set :which-key-delay 400)`))
+	send("\x02")
+	time.Sleep(100 * time.Millisecond)
+	require.False(t, isShown("name-e"))
+	require.Eventually(t, func() bool {
+		return isShown("name-e")
+	}, 2*time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool {
+		return !isShown("name-e")
+	}, 3*time.Second, 10*time.Millisecond)
+
+	// And not at all when which-key is disabled
+	require.NoError(t, client.execute(`
+(cy/set :which-key false)
+(cy/set :which-key-delay 0)
+`))
+	send("\x02")
+	time.Sleep(100 * time.Millisecond)
+	require.False(t, isShown("name-e"))
+}
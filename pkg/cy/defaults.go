@@ -6,9 +6,22 @@ import (
 
 func (c *Cy) setDefaults(options Options) error {
 	defaults := map[string]interface{}{
-		params.ParamDataDirectory: options.DataDir,
-		params.ParamAnimate:       true,
-		params.ParamDefaultShell:  options.Shell,
+		params.ParamDataDirectory:        options.DataDir,
+		params.ParamAnimate:              true,
+		params.ParamDefaultShell:         options.Shell,
+		params.ParamHistoryLimit:         10000,
+		params.ParamMonitorBell:          true,
+		params.ParamMonitorActivity:      0,
+		params.ParamMonitorSilence:       0,
+		params.ParamForwardNotifications: false,
+		params.ParamKeyTimeout:           1000,
+		params.ParamRepeatTimeout:        500,
+		params.ParamKeyCounts:            false,
+		params.ParamWhichKey:             false,
+		params.ParamWhichKeyDelay:        500,
+		params.ParamStatusLine:           false,
+		params.ParamStatusPosition:       "bottom",
+		params.ParamScrollReplay:         true,
 	}
 
 	for key, value := range defaults {
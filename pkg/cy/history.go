@@ -0,0 +1,180 @@
+package cy
+
+import (
+	_ "embed"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cfoust/cy/pkg/janet"
+	"github.com/cfoust/cy/pkg/mux/screen/tree"
+)
+
+// frecency combines how many times a node was visited with how recently it
+// was used, much like zoxide ranks directories. Nodes used within the last
+// hour rank highest.
+func frecency(visits int, lastUse time.Time, now time.Time) float64 {
+	score := float64(visits)
+	age := now.Sub(lastUse)
+	switch {
+	case age < time.Hour:
+		return score * 4
+	case age < 24*time.Hour:
+		return score * 2
+	case age < 7*24*time.Hour:
+		return score / 2
+	default:
+		return score / 4
+	}
+}
+
+// countVisit records that a client attached to the node `id`.
+func (c *Cy) countVisit(id tree.NodeID) {
+	c.Lock()
+	c.visitCounts[id]++
+	c.Unlock()
+}
+
+// getLastUse returns the last time any client visited or wrote to the node
+// `id`.
+func (c *Cy) getLastUse(id tree.NodeID) (stamp time.Time, ok bool) {
+	visit, haveVisit := c.lastVisit[id]
+	write, haveWrite := c.lastWrite[id]
+	if haveVisit {
+		stamp, ok = visit.Stamp, true
+	}
+	if haveWrite && write.Stamp.After(stamp) {
+		stamp, ok = write.Stamp, true
+	}
+	return
+}
+
+type nodeUsage struct {
+	id       tree.NodeID
+	lastUse  time.Time
+	frecency float64
+}
+
+// getUsage returns information about how every node that still exists has
+// been used. Nodes that no client has used are omitted.
+func (c *Cy) getUsage() (usage []nodeUsage) {
+	now := time.Now()
+
+	c.RLock()
+	ids := make(map[tree.NodeID]struct{})
+	for id := range c.lastVisit {
+		ids[id] = struct{}{}
+	}
+	for id := range c.lastWrite {
+		ids[id] = struct{}{}
+	}
+
+	for id := range ids {
+		lastUse, _ := c.getLastUse(id)
+		usage = append(usage, nodeUsage{
+			id:       id,
+			lastUse:  lastUse,
+			frecency: frecency(c.visitCounts[id], lastUse, now),
+		})
+	}
+	c.RUnlock()
+
+	existing := usage[:0]
+	for _, node := range usage {
+		if _, ok := c.tree.NodeById(node.id); !ok {
+			continue
+		}
+		existing = append(existing, node)
+	}
+	return existing
+}
+
+// previousNode returns the node this client was most recently attached to,
+// other than the current one, that still exists.
+func (c *Client) previousNode() (tree.Node, bool) {
+	c.RLock()
+	history := c.history
+	current := c.node
+	c.RUnlock()
+
+	for i := len(history) - 1; i >= 0; i-- {
+		if current != nil && history[i] == current.Id() {
+			continue
+		}
+
+		node, ok := c.cy.tree.NodeById(history[i])
+		if !ok {
+			continue
+		}
+		return node, true
+	}
+
+	return nil, false
+}
+
+//go:embed docs-history.md
+var DOCS_HISTORY string
+
+type HistoryModule struct {
+	cy *Cy
+}
+
+var _ janet.Documented = (*HistoryModule)(nil)
+
+func (h *HistoryModule) Documentation() string {
+	return DOCS_HISTORY
+}
+
+func getIds(usage []nodeUsage) []tree.NodeID {
+	ids := make([]tree.NodeID, len(usage))
+	for i, node := range usage {
+		ids[i] = node.id
+	}
+	return ids
+}
+
+func (h *HistoryModule) Frecent() []tree.NodeID {
+	usage := h.cy.getUsage()
+	sort.SliceStable(usage, func(i, j int) bool {
+		if usage[i].frecency != usage[j].frecency {
+			return usage[i].frecency > usage[j].frecency
+		}
+		return usage[i].lastUse.After(usage[j].lastUse)
+	})
+	return getIds(usage)
+}
+
+func (h *HistoryModule) Recent() []tree.NodeID {
+	usage := h.cy.getUsage()
+	sort.SliceStable(usage, func(i, j int) bool {
+		return usage[i].lastUse.After(usage[j].lastUse)
+	})
+	return getIds(usage)
+}
+
+func (h *HistoryModule) Frecency(id tree.NodeID) float64 {
+	h.cy.RLock()
+	defer h.cy.RUnlock()
+
+	lastUse, ok := h.cy.getLastUse(id)
+	if !ok {
+		return 0
+	}
+
+	return frecency(h.cy.visitCounts[id], lastUse, time.Now())
+}
+
+func (h *HistoryModule) Previous(user interface{}) (*tree.NodeID, error) {
+	client, ok := user.(*Client)
+	if !ok {
+		return nil, fmt.Errorf("missing client context")
+	}
+
+	node, ok := client.previousNode()
+	if !ok {
+		return nil, nil
+	}
+
+	id := node.Id()
+	return &id, nil
+}
@@ -0,0 +1,242 @@
+package cy
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+
+	"github.com/cfoust/cy/pkg/janet"
+
+	"github.com/sasha-s/go-deadlock"
+)
+
+// Hook is the name of an event that Janet code can react to.
+type Hook string
+
+const (
+	HookPaneCreate   Hook = "pane-create"
+	HookPaneExit     Hook = "pane-exit"
+	HookPaneTitle    Hook = "pane-title"
+	HookPaneAttach   Hook = "pane-attach"
+	HookPaneBell     Hook = "pane-bell"
+	HookPaneActivity Hook = "pane-activity"
+	HookPaneSilence  Hook = "pane-silence"
+	HookNodeRename   Hook = "node-rename"
+	HookClientAttach Hook = "client-attach"
+	HookClientDetach Hook = "client-detach"
+	HookConfigReload Hook = "config-reload"
+)
+
+var validHooks = map[Hook]struct{}{
+	HookPaneCreate:   {},
+	HookPaneExit:     {},
+	HookPaneTitle:    {},
+	HookPaneAttach:   {},
+	HookPaneBell:     {},
+	HookPaneActivity: {},
+	HookPaneSilence:  {},
+	HookNodeRename:   {},
+	HookClientAttach: {},
+	HookClientDetach: {},
+	HookConfigReload: {},
+}
+
+type hookCallback struct {
+	id       int32
+	callback *janet.Function
+}
+
+// hookRun is a hook that fired whose functions have not been called yet.
+type hookRun struct {
+	user      interface{}
+	hook      Hook
+	args      []interface{}
+	callbacks []hookCallback
+}
+
+// hookRegistry stores the Janet functions that should be called when each
+// hook fires.
+type hookRegistry struct {
+	deadlock.RWMutex
+	nextID    int32
+	callbacks map[Hook][]hookCallback
+
+	// Hooks that fired, in order. They are run one at a time so that
+	// hooks for events that follow one another run in the same order.
+	queue []hookRun
+	// signals that `queue` is no longer empty
+	pending chan struct{}
+}
+
+func newHookRegistry() *hookRegistry {
+	return &hookRegistry{
+		callbacks: make(map[Hook][]hookCallback),
+		pending:   make(chan struct{}, 1),
+	}
+}
+
+func (h *hookRegistry) add(hook Hook, callback *janet.Function) int32 {
+	h.Lock()
+	defer h.Unlock()
+
+	h.nextID++
+	h.callbacks[hook] = append(h.callbacks[hook], hookCallback{
+		id:       h.nextID,
+		callback: callback,
+	})
+	return h.nextID
+}
+
+func (h *hookRegistry) remove(id int32) bool {
+	h.Lock()
+	var removed *janet.Function
+	for hook, callbacks := range h.callbacks {
+		for i, callback := range callbacks {
+			if callback.id != id {
+				continue
+			}
+
+			newCallbacks := make([]hookCallback, 0, len(callbacks)-1)
+			newCallbacks = append(newCallbacks, callbacks[:i]...)
+			newCallbacks = append(newCallbacks, callbacks[i+1:]...)
+			h.callbacks[hook] = newCallbacks
+			removed = callback.callback
+			break
+		}
+	}
+	h.Unlock()
+
+	if removed == nil {
+		return false
+	}
+
+	removed.Free()
+	return true
+}
+
+func (h *hookRegistry) get(hook Hook) []hookCallback {
+	h.RLock()
+	defer h.RUnlock()
+	return h.callbacks[hook]
+}
+
+// enqueue adds `run` to the end of the queue without waiting for the hooks
+// before it to finish, since hooks can cause events of their own.
+func (h *hookRegistry) enqueue(run hookRun) {
+	h.Lock()
+	h.queue = append(h.queue, run)
+	h.Unlock()
+
+	select {
+	case h.pending <- struct{}{}:
+	default:
+	}
+}
+
+func (h *hookRegistry) dequeue() (run hookRun, ok bool) {
+	h.Lock()
+	defer h.Unlock()
+
+	if len(h.queue) == 0 {
+		return
+	}
+
+	run, h.queue = h.queue[0], h.queue[1:]
+	return run, true
+}
+
+// runHooks calls every function registered for `hook` in the order they
+// were added, after the functions for any hooks that fired before it.
+// `user` is passed to the functions as their client context and may be
+// nil.
+func (c *Cy) runHooks(user interface{}, hook Hook, args ...interface{}) {
+	callbacks := c.hooks.get(hook)
+	if len(callbacks) == 0 {
+		return
+	}
+
+	c.hooks.enqueue(hookRun{
+		user:      user,
+		hook:      hook,
+		args:      args,
+		callbacks: callbacks,
+	})
+}
+
+func (c *Cy) callHooks(run hookRun) {
+	for _, callback := range run.callbacks {
+		err := callback.callback.CallContext(
+			c.Ctx(),
+			run.user,
+			run.args...,
+		)
+		// The hook may have been removed since we started
+		if err == nil || err == context.Canceled || err == janet.ERROR_FREED {
+			continue
+		}
+
+		c.log.Error().Err(err).Msgf("failed to run hook %s", run.hook)
+		c.toast.Error(fmt.Sprintf(
+			"an error occurred while running hook %s: %s",
+			run.hook,
+			err.Error(),
+		))
+	}
+}
+
+func (c *Cy) pollHooks(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.hooks.pending:
+		}
+
+		for {
+			run, ok := c.hooks.dequeue()
+			if !ok {
+				break
+			}
+
+			c.callHooks(run)
+		}
+	}
+}
+
+//go:embed docs-hook.md
+var DOCS_HOOK string
+
+type HookModule struct {
+	hooks *hookRegistry
+}
+
+var _ janet.Documented = (*HookModule)(nil)
+
+func (h *HookModule) Documentation() string {
+	return DOCS_HOOK
+}
+
+func (h *HookModule) Add(event *janet.Value, callback *janet.Function) (int32, error) {
+	defer event.Free()
+
+	var keyword janet.Keyword
+	err := event.Unmarshal(&keyword)
+	if err != nil {
+		return 0, err
+	}
+
+	hook := Hook(keyword)
+	if _, ok := validHooks[hook]; !ok {
+		return 0, fmt.Errorf("unknown hook: %s", keyword)
+	}
+
+	return h.hooks.add(hook, callback), nil
+}
+
+func (h *HookModule) Remove(id int32) error {
+	if !h.hooks.remove(id) {
+		return fmt.Errorf("hook %d does not exist", id)
+	}
+
+	return nil
+}
@@ -3,8 +3,10 @@ package cy
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/cfoust/cy/pkg/cy/api"
+	cyParams "github.com/cfoust/cy/pkg/cy/params"
 	"github.com/cfoust/cy/pkg/janet"
 	"github.com/cfoust/cy/pkg/mux/screen/replayable"
 	"github.com/cfoust/cy/pkg/mux/screen/toasts"
@@ -119,6 +121,16 @@ func (c *CyModule) Set(user interface{}, key *janet.Value, value *janet.Value) e
 		return err
 	}
 
+	// Parameters such as :status-line change what clients see
+	defer c.cy.refreshStatus()
+
+	switch string(keyword) {
+	case cyParams.ParamKeyTimeout,
+		cyParams.ParamRepeatTimeout,
+		cyParams.ParamKeyCounts:
+		defer c.cy.configureBinds()
+	}
+
 	var str string
 	err = value.Unmarshal(&str)
 	if err == nil {
@@ -133,7 +145,7 @@ func (c *CyModule) Set(user interface{}, key *janet.Value, value *janet.Value) e
 		return nil
 	}
 
-	var _bool int
+	var _bool bool
 	err = value.Unmarshal(&_bool)
 	if err == nil {
 		node.Params().Set(string(keyword), _bool)
@@ -225,6 +237,50 @@ func (c *CyModule) Log(level *janet.Value, text string) error {
 	return nil
 }
 
+func (c *CyModule) ReloadConfig(user interface{}, ctx context.Context) error {
+	if len(c.cy.configPath) == 0 {
+		return fmt.Errorf("no configuration file was loaded")
+	}
+
+	c.cy.loadUserConfig(ctx)
+	c.cy.runHooks(user, HookConfigReload)
+	return nil
+}
+
+func (c *CyModule) After(user interface{}, ms int, callback *janet.Function) (int32, error) {
+	if ms < 0 {
+		return 0, fmt.Errorf("delay must not be negative")
+	}
+
+	return c.cy.runTimer(
+		user,
+		time.Duration(ms)*time.Millisecond,
+		false,
+		callback,
+	), nil
+}
+
+func (c *CyModule) Every(user interface{}, ms int, callback *janet.Function) (int32, error) {
+	if ms <= 0 {
+		return 0, fmt.Errorf("interval must be greater than zero")
+	}
+
+	return c.cy.runTimer(
+		user,
+		time.Duration(ms)*time.Millisecond,
+		true,
+		callback,
+	), nil
+}
+
+func (c *CyModule) Cancel(id int32) error {
+	if !c.cy.timers.remove(id) {
+		return fmt.Errorf("timer %d does not exist", id)
+	}
+
+	return nil
+}
+
 func (c *Cy) initJanet(ctx context.Context) (*janet.VM, error) {
 	vm, err := janet.New(ctx)
 	if err != nil {
@@ -241,16 +297,21 @@ func (c *Cy) initJanet(ctx context.Context) (*janet.VM, error) {
 		"key": &api.KeyModule{
 			Tree:        c.tree,
 			ReplayBinds: c.replayBinds,
+			Tables:      c.keyTables,
 		},
-		"group": &api.GroupModule{Tree: c.tree},
-		"input": &api.InputModule{Tree: c.tree, Server: c.muxServer},
-		"pane":  &api.PaneModule{Tree: c.tree},
-		"path":  &api.PathModule{},
+		"group":   &api.GroupModule{Tree: c.tree},
+		"history": &HistoryModule{cy: c},
+		"hook":    &HookModule{hooks: c.hooks},
+		"input":   &api.InputModule{Tree: c.tree, Server: c.muxServer},
+		"macro":   &MacroModule{macros: c.macros, tree: c.tree},
+		"pane":    &api.PaneModule{Tree: c.tree},
+		"path":    &api.PathModule{},
 		"replay": &api.ReplayModule{
 			Lifetime: util.NewLifetime(c.Ctx()),
 			Tree:     c.tree,
 			Binds:    c.replayBinds,
 		},
+		"status":   &StatusModule{cy: c},
 		"tree":     &api.TreeModule{Tree: c.tree},
 		"viewport": &api.ViewportModule{},
 	}
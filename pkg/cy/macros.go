@@ -0,0 +1,335 @@
+package cy
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/cfoust/cy/pkg/cy/api"
+	"github.com/cfoust/cy/pkg/cy/params"
+	"github.com/cfoust/cy/pkg/janet"
+	"github.com/cfoust/cy/pkg/mux/screen/tree"
+	"github.com/cfoust/cy/pkg/taro"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sasha-s/go-deadlock"
+)
+
+// macroFile is the name of the file in the data directory that macros are
+// saved to.
+const macroFile = "macros.json"
+
+// macroRegistry stores keyboard macros in named registers. A macro is a
+// list of keys in the format produced by taro.KeyMsg.String().
+type macroRegistry struct {
+	deadlock.RWMutex
+	// getDir returns the directory in which registers are persisted.
+	// If it returns an empty string, registers only live in memory.
+	getDir    func() string
+	loaded    bool
+	registers map[string][]string
+}
+
+func newMacroRegistry(getDir func() string) *macroRegistry {
+	return &macroRegistry{
+		getDir:    getDir,
+		registers: make(map[string][]string),
+	}
+}
+
+// load reads registers from disk the first time they're needed, which
+// gives the user's configuration a chance to change :data-dir. The caller
+// must hold the lock.
+func (m *macroRegistry) load() {
+	if m.loaded {
+		return
+	}
+	m.loaded = true
+
+	dir := m.getDir()
+	if len(dir) == 0 {
+		return
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, macroFile))
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("failed to read macros")
+		return
+	}
+
+	err = json.Unmarshal(data, &m.registers)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to parse macros")
+	}
+}
+
+// save writes all registers to disk. The caller must hold the lock.
+func (m *macroRegistry) save() error {
+	dir := m.getDir()
+	if len(dir) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(m.registers)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, macroFile), data, 0644)
+}
+
+func (m *macroRegistry) get(name string) ([]string, bool) {
+	m.Lock()
+	defer m.Unlock()
+	m.load()
+
+	keys, ok := m.registers[name]
+	return keys, ok
+}
+
+// set replaces the contents of the register `name` with `keys`. An empty
+// list of keys clears the register.
+func (m *macroRegistry) set(name string, keys []string) error {
+	m.Lock()
+	defer m.Unlock()
+	m.load()
+
+	if len(keys) == 0 {
+		delete(m.registers, name)
+	} else {
+		m.registers[name] = keys
+	}
+
+	return m.save()
+}
+
+func (m *macroRegistry) names() (names []string) {
+	m.Lock()
+	defer m.Unlock()
+	m.load()
+
+	for name := range m.registers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+func (c *Cy) getDataDir() string {
+	value, _ := c.tree.Root().Params().Get(params.ParamDataDirectory)
+	dir, _ := value.(string)
+	return dir
+}
+
+// StartMacro begins recording the keys the client sends to its node into
+// the register `name`.
+func (c *Client) StartMacro(name string) error {
+	if len(name) == 0 {
+		return fmt.Errorf("register name must not be empty")
+	}
+
+	c.Lock()
+	if len(c.macroRegister) > 0 {
+		register := c.macroRegister
+		c.Unlock()
+		return fmt.Errorf(
+			"already recording into register %s",
+			register,
+		)
+	}
+
+	c.macroRegister = name
+	c.macroKeys = nil
+	c.Unlock()
+
+	c.refreshStatus()
+	return nil
+}
+
+// StopMacro stops recording and returns the name of the register that was
+// being recorded into along with the keys that were recorded. `ok` is false
+// if the client was not recording.
+func (c *Client) StopMacro() (name string, keys []string, ok bool) {
+	c.Lock()
+	name, keys = c.macroRegister, c.macroKeys
+	c.macroRegister = ""
+	c.macroKeys = nil
+
+	// The keys that triggered the binding that stopped recording are not
+	// part of the macro
+	if n := len(keys) - len(c.lastSequence); n >= 0 && slices.Equal(keys[n:], c.lastSequence) {
+		keys = keys[:n]
+	}
+	c.Unlock()
+
+	c.refreshStatus()
+	return name, keys, len(name) > 0
+}
+
+// MacroRegister returns the name of the register the client is recording
+// into, if any.
+func (c *Client) MacroRegister() string {
+	c.RLock()
+	defer c.RUnlock()
+	return c.macroRegister
+}
+
+// recordInput records the keys in `data` if the client is recording a
+// macro. This happens before the keys reach the client's binding engine so
+// that macros also contain the sequences that trigger bindings.
+func (c *Client) recordInput(data []byte) {
+	c.Lock()
+	defer c.Unlock()
+
+	if len(c.macroRegister) == 0 {
+		return
+	}
+
+	for i, w := 0, 0; i < len(data); i += w {
+		var msg taro.Msg
+		w, msg = taro.DetectOneMsg(data[i:])
+
+		key, ok := msg.(taro.KeyMsg)
+		if !ok || key.Event == taro.KeyEventRelease {
+			continue
+		}
+
+		str := key.String()
+		if len(str) == 0 {
+			continue
+		}
+
+		c.macroKeys = append(c.macroKeys, str)
+	}
+}
+
+//go:embed docs-macro.md
+var DOCS_MACRO string
+
+type MacroModule struct {
+	macros *macroRegistry
+	tree   *tree.Tree
+}
+
+var _ janet.Documented = (*MacroModule)(nil)
+
+func (m *MacroModule) Documentation() string {
+	return DOCS_MACRO
+}
+
+func (m *MacroModule) Record(user interface{}, name string) error {
+	client, ok := user.(*Client)
+	if !ok {
+		return fmt.Errorf("missing client context")
+	}
+
+	return client.StartMacro(name)
+}
+
+func (m *MacroModule) Stop(user interface{}) (*string, error) {
+	client, ok := user.(*Client)
+	if !ok {
+		return nil, fmt.Errorf("missing client context")
+	}
+
+	name, keys, ok := client.StopMacro()
+	if !ok {
+		return nil, nil
+	}
+
+	err := m.macros.set(name, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &name, nil
+}
+
+func (m *MacroModule) Recording(user interface{}) (*string, error) {
+	client, ok := user.(*Client)
+	if !ok {
+		return nil, fmt.Errorf("missing client context")
+	}
+
+	name := client.MacroRegister()
+	if len(name) == 0 {
+		return nil, nil
+	}
+
+	return &name, nil
+}
+
+type MacroPlayParams struct {
+	Times int
+	Pane  *tree.NodeID
+}
+
+func (m *MacroModule) Play(
+	user interface{},
+	name string,
+	named *janet.Named[MacroPlayParams],
+) error {
+	params := named.WithDefault(MacroPlayParams{
+		Times: 1,
+	})
+	if params.Times < 1 {
+		return fmt.Errorf("times must be at least 1")
+	}
+
+	keys, ok := m.macros.get(name)
+	if !ok {
+		return fmt.Errorf("register %s is empty", name)
+	}
+
+	// Keys sent to a pane skip bindings entirely
+	if params.Pane != nil {
+		panes := &api.PaneModule{Tree: m.tree}
+		for i := 0; i < params.Times; i++ {
+			if err := panes.SendKeys(*params.Pane, keys); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	client, ok := user.(*Client)
+	if !ok {
+		return fmt.Errorf("missing client context")
+	}
+
+	// Keys go through the client's bindings just as they would have if
+	// the client typed them, so macros can trigger actions too
+	msgs := taro.KeysToMsg(keys...)
+	for i := 0; i < params.Times; i++ {
+		for _, msg := range msgs {
+			client.binds.InputMessage(msg)
+		}
+	}
+
+	return nil
+}
+
+func (m *MacroModule) Get(name string) []string {
+	keys, _ := m.macros.get(name)
+	return keys
+}
+
+func (m *MacroModule) Set(name string, keys []string) error {
+	return m.macros.set(name, keys)
+}
+
+func (m *MacroModule) List() []string {
+	return m.macros.names()
+}
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/cfoust/cy/pkg/bind"
+	cyParams "github.com/cfoust/cy/pkg/cy/params"
+	"github.com/cfoust/cy/pkg/emu"
 	"github.com/cfoust/cy/pkg/events"
 	"github.com/cfoust/cy/pkg/geom"
 	"github.com/cfoust/cy/pkg/janet"
@@ -60,6 +62,9 @@ type Cy struct {
 	// Replay mode has its own isolated binding scope
 	replayBinds *bind.BindScope
 
+	// Named key tables that clients can switch into
+	keyTables *bind.Tables
+
 	clients []*Client
 
 	log zerolog.Logger
@@ -74,6 +79,26 @@ type Cy struct {
 	// (tmux does the same thing)
 	lastWrite, lastVisit map[tree.NodeID]historyEvent
 	writes, visits       chan historyEvent
+	// The number of times clients have attached to each node, which is
+	// used to rank nodes by frecency
+	visitCounts map[tree.NodeID]int
+
+	// The last time each pane produced output, which we use to detect
+	// activity and silence, and the panes we've already reported as silent
+	lastOutput map[tree.NodeID]historyEvent
+	silenced   map[tree.NodeID]bool
+	// Whether each pane rang the bell, became active, or went silent
+	// since a client last visited it
+	alerts map[tree.NodeID]alert
+
+	// Janet functions registered with (hook/add)
+	hooks *hookRegistry
+	// Timers created with (cy/after) and (cy/every)
+	timers *timerRegistry
+	// Keyboard macros recorded with (macro/record)
+	macros *macroRegistry
+	// The function set with (status/set-render)
+	statusRender *janet.Function
 }
 
 func (c *Cy) loadUserConfig(ctx context.Context) {
@@ -182,6 +207,35 @@ func (c *Cy) pollNodeEvents(ctx context.Context, events <-chan events.Msg) {
 				continue
 			}
 
+			// These do not depend on who last used the pane
+			switch event := nodeEvent.Event.(type) {
+			case tree.CreateEvent:
+				if _, ok := c.tree.PaneById(nodeEvent.Id); ok {
+					c.runHooks(nil, HookPaneCreate, nodeEvent.Id)
+				}
+				continue
+			case tree.RenameEvent:
+				c.runHooks(nil, HookNodeRename, nodeEvent.Id, event.Name)
+				c.refreshStatus()
+				continue
+			case screen.TitleEvent:
+				c.runHooks(nil, HookPaneTitle, nodeEvent.Id, event.Title)
+				c.refreshStatus()
+				continue
+			case stream.ExitEvent:
+				c.runHooks(nil, HookPaneExit, nodeEvent.Id, event.Code)
+				continue
+			case screen.NotificationEvent:
+				go c.handleNotification(nodeEvent.Id, event.Notification)
+				continue
+			case screen.BellEvent:
+				go c.handleBell(nodeEvent.Id)
+				continue
+			case screen.OutputEvent:
+				c.handleOutput(nodeEvent.Id)
+				continue
+			}
+
 			client, ok := c.inferClient(nodeEvent.Id)
 			if !ok {
 				continue
@@ -208,13 +262,21 @@ func Start(ctx context.Context, options Options) (*Cy, error) {
 		tree:        t,
 		muxServer:   server.New(),
 		replayBinds: replayBinds,
+		keyTables:   bind.NewTables(),
 		defaults:    defaults,
 		lastVisit:   make(map[tree.NodeID]historyEvent),
 		lastWrite:   make(map[tree.NodeID]historyEvent),
+		visitCounts: make(map[tree.NodeID]int),
+		lastOutput:  make(map[tree.NodeID]historyEvent),
+		silenced:    make(map[tree.NodeID]bool),
+		alerts:      make(map[tree.NodeID]alert),
+		hooks:       newHookRegistry(),
+		timers:      newTimerRegistry(),
 		writes:      make(chan historyEvent),
 		visits:      make(chan historyEvent),
 	}
 	cy.toast = NewToastLogger(cy.sendToast)
+	cy.macros = newMacroRegistry(cy.getDataDir)
 	err := cy.setDefaults(options)
 	if err != nil {
 		return nil, err
@@ -224,9 +286,18 @@ func Start(ctx context.Context, options Options) (*Cy, error) {
 	go cy.pollNodeEvents(cy.Ctx(), subscriber.Recv())
 	go cy.pollInteractions(cy.Ctx(), cy.lastWrite, cy.writes)
 	go cy.pollInteractions(cy.Ctx(), cy.lastVisit, cy.visits)
+	go cy.pollSilence(cy.Ctx())
+	go cy.pollHooks(cy.Ctx())
 
 	logs := stream.NewReader()
-	terminal := screen.NewTerminal(cy.Ctx(), logs, geom.DEFAULT_SIZE)
+	historyLimit, _ := defaults.Get(cyParams.ParamHistoryLimit)
+	limit, _ := historyLimit.(int)
+	terminal := screen.NewTerminal(
+		cy.Ctx(),
+		logs,
+		geom.DEFAULT_SIZE,
+		emu.WithHistoryLimit(limit),
+	)
 	logPane := t.Root().NewPane(cy.Ctx(), terminal)
 	logPane.SetName("logs")
 
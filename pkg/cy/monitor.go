@@ -0,0 +1,169 @@
+package cy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cfoust/cy/pkg/cy/api"
+	"github.com/cfoust/cy/pkg/cy/params"
+	"github.com/cfoust/cy/pkg/mux/screen/toasts"
+	"github.com/cfoust/cy/pkg/mux/screen/tree"
+)
+
+// How often we check panes for silence.
+const silenceInterval = time.Second
+
+// getNodeSeconds reads a parameter representing a duration in seconds from
+// the given node. Non-positive values disable the monitor in question.
+func getNodeSeconds(node tree.Node, key string) (time.Duration, bool) {
+	value, ok := node.Params().Get(key)
+	if !ok {
+		return 0, false
+	}
+
+	seconds, ok := value.(int)
+	if !ok || seconds <= 0 {
+		return 0, false
+	}
+
+	return time.Duration(seconds) * time.Second, true
+}
+
+// notifyBackground sends a toast about a node to every client that is not
+// currently attached to it.
+func (c *Cy) notifyBackground(node tree.Node, level toasts.ToastLevel, format string) {
+	name := node.Name()
+	if path := (&api.TreeModule{Tree: c.tree}).Path(node.Id()); path != nil {
+		name = *path
+	}
+
+	toast := toasts.Toast{
+		Message: fmt.Sprintf(format, name),
+		Level:   level,
+	}
+
+	c.RLock()
+	clients := c.clients
+	c.RUnlock()
+
+	for _, client := range clients {
+		if attached := client.Node(); attached != nil && attached.Id() == node.Id() {
+			continue
+		}
+		client.toast.Send(toast)
+	}
+}
+
+// handleBell is called when a pane rings the bell.
+func (c *Cy) handleBell(id tree.NodeID) {
+	node, ok := c.tree.NodeById(id)
+	if !ok {
+		return
+	}
+
+	c.runHooks(nil, HookPaneBell, id)
+
+	value, ok := node.Params().Get(params.ParamMonitorBell)
+	if enabled, _ := value.(bool); !ok || !enabled {
+		return
+	}
+
+	c.raiseAlert(node, alertBell)
+	c.notifyBackground(node, toasts.ToastLevelWarn, "%s rang the bell")
+}
+
+// handleOutput records that a pane produced output and notifies clients if
+// it had been idle for longer than its monitor-activity threshold.
+func (c *Cy) handleOutput(id tree.NodeID) {
+	now := time.Now()
+
+	c.Lock()
+	last, haveLast := c.lastOutput[id]
+	c.lastOutput[id] = historyEvent{
+		Stamp: now,
+		Node:  id,
+	}
+	delete(c.silenced, id)
+	c.Unlock()
+
+	if !haveLast {
+		return
+	}
+
+	node, ok := c.tree.NodeById(id)
+	if !ok {
+		return
+	}
+
+	threshold, ok := getNodeSeconds(node, params.ParamMonitorActivity)
+	if !ok || now.Sub(last.Stamp) < threshold {
+		return
+	}
+
+	c.runHooks(nil, HookPaneActivity, id)
+	go c.raiseAlert(node, alertActivity)
+	go c.notifyBackground(node, toasts.ToastLevelInfo, "%s is active")
+}
+
+// checkSilence notifies clients about every pane that has not produced
+// output for longer than its monitor-silence threshold. Each pane is only
+// reported once per period of silence.
+func (c *Cy) checkSilence(now time.Time) {
+	c.RLock()
+	outputs := make([]historyEvent, 0, len(c.lastOutput))
+	for id, event := range c.lastOutput {
+		if c.silenced[id] {
+			continue
+		}
+		outputs = append(outputs, event)
+	}
+	c.RUnlock()
+
+	for _, event := range outputs {
+		node, ok := c.tree.NodeById(event.Node)
+		if !ok {
+			c.Lock()
+			delete(c.lastOutput, event.Node)
+			delete(c.silenced, event.Node)
+			c.Unlock()
+			continue
+		}
+
+		threshold, ok := getNodeSeconds(node, params.ParamMonitorSilence)
+		if !ok || now.Sub(event.Stamp) < threshold {
+			continue
+		}
+
+		// The pane may have produced output in the meantime
+		c.Lock()
+		current := c.lastOutput[event.Node]
+		stale := !current.Stamp.Equal(event.Stamp)
+		if !stale {
+			c.silenced[event.Node] = true
+		}
+		c.Unlock()
+
+		if stale {
+			continue
+		}
+
+		c.runHooks(nil, HookPaneSilence, event.Node)
+		c.raiseAlert(node, alertSilence)
+		c.notifyBackground(node, toasts.ToastLevelInfo, "%s went silent")
+	}
+}
+
+func (c *Cy) pollSilence(ctx context.Context) {
+	ticker := time.NewTicker(silenceInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			c.checkSilence(now)
+		}
+	}
+}
@@ -10,4 +10,54 @@ const (
 	// The default shell with which to start panes.
 	// string, default: /bin/bash, but also $SHELL
 	ParamDefaultShell = "default-shell"
+	// The maximum number of lines in each pane's scrollback buffer. Only
+	// affects panes created after it is set. Zero means unlimited.
+	// int, default: 10000
+	ParamHistoryLimit = "history-limit"
+	// Whether to notify clients when a pane they are not attached to
+	// rings the bell.
+	// boolean, default: true
+	ParamMonitorBell = "monitor-bell"
+	// Notify clients when a pane produces output after being silent for at
+	// least this many seconds. Zero disables activity monitoring.
+	// int, default: 0
+	ParamMonitorActivity = "monitor-activity"
+	// Notify clients when a pane has not produced any output for this many
+	// seconds. Zero disables silence monitoring.
+	// int, default: 0
+	ParamMonitorSilence = "monitor-silence"
+	// Whether to pass notifications sent by panes (OSC 9 and OSC 777) on
+	// to the client's terminal in addition to showing them as toasts.
+	// boolean, default: false
+	ParamForwardNotifications = "forward-notifications"
+	// How long, in milliseconds, the user has to type the next key in a
+	// key sequence.
+	// int, default: 1000
+	ParamKeyTimeout = "key-timeout"
+	// How long, in milliseconds, the user has to repeat a repeatable
+	// binding without typing its prefix again.
+	// int, default: 500
+	ParamRepeatTimeout = "repeat-timeout"
+	// Whether a number typed before a key sequence is passed to its
+	// binding as a count.
+	// boolean, default: false
+	ParamKeyCounts = "key-counts"
+	// Whether to show the keys that can follow a partially typed key
+	// sequence.
+	// boolean, default: false
+	ParamWhichKey = "which-key"
+	// How long, in milliseconds, to wait after a key is typed before
+	// showing the possible continuations.
+	// int, default: 500
+	ParamWhichKeyDelay = "which-key-delay"
+	// Whether to show the status line.
+	// boolean, default: false
+	ParamStatusLine = "status-line"
+	// Where to show the status line, either "top" or "bottom".
+	// string, default: "bottom"
+	ParamStatusPosition = "status-position"
+	// Whether scrolling up in a pane enters replay mode, unless the
+	// process running in the pane handles scrolling itself.
+	// boolean, default: true
+	ParamScrollReplay = "scroll-replay"
 )
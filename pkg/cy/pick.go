@@ -0,0 +1,185 @@
+package cy
+
+import (
+	"context"
+	"fmt"
+
+	cyParams "github.com/cfoust/cy/pkg/cy/params"
+	"github.com/cfoust/cy/pkg/fuzzy"
+	"github.com/cfoust/cy/pkg/geom"
+	"github.com/cfoust/cy/pkg/io/pipe"
+	P "github.com/cfoust/cy/pkg/io/protocol"
+	"github.com/cfoust/cy/pkg/mux/screen"
+	"github.com/cfoust/cy/pkg/mux/screen/replayable"
+	"github.com/cfoust/cy/pkg/mux/screen/tree"
+	"github.com/cfoust/cy/pkg/mux/stream"
+)
+
+// findSessionPane returns the pane whose command leads the process session
+// `session`. Panes start their commands in new sessions, so every process
+// started inside of a pane shares its session unless it creates its own.
+func (c *Cy) findSessionPane(session int) (*tree.Pane, bool) {
+	for _, node := range c.tree.Leaves() {
+		pane, ok := node.(*tree.Pane)
+		if !ok {
+			continue
+		}
+
+		r, ok := pane.Screen().(*replayable.Replayable)
+		if !ok {
+			continue
+		}
+
+		cmd, ok := r.Stream().(*stream.Cmd)
+		if !ok {
+			continue
+		}
+
+		if pid, err := cmd.Pid(); err == nil && pid == session {
+			return pane, true
+		}
+	}
+
+	return nil, false
+}
+
+// findPaneClient returns the client attached to the pane `id`, or the one
+// that used it last if no client is attached to it.
+func (c *Cy) findPaneClient(id tree.NodeID) (*Client, bool) {
+	c.RLock()
+	clients := c.clients
+	c.RUnlock()
+
+	for _, client := range clients {
+		if node := client.Node(); node != nil && node.Id() == id {
+			return client, true
+		}
+	}
+
+	return c.inferClient(id)
+}
+
+// readOptions turns the OptionsMessages sent by `cy pick` into a stream of
+// options for the fuzzy finder.
+func readOptions(
+	ctx context.Context,
+	events <-chan pipe.Packet[P.Message],
+) <-chan fuzzy.Option {
+	options := make(chan fuzzy.Option)
+	go func() {
+		defer close(options)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case packet, more := <-events:
+				if !more || packet.Error != nil {
+					return
+				}
+
+				msg, ok := packet.Contents.(*P.OptionsMessage)
+				if !ok {
+					continue
+				}
+
+				for _, line := range msg.Options {
+					select {
+					case options <- fuzzy.NewOption(line, line):
+					case <-ctx.Done():
+						return
+					}
+				}
+
+				if msg.Done {
+					return
+				}
+			}
+		}
+	}()
+
+	return options
+}
+
+// pick shows the fuzzy finder to the client using the pane in which `cy
+// pick` was run and replies with the options the user chose.
+func (c *Cy) pick(
+	conn Connection,
+	request *P.PickMessage,
+	events <-chan pipe.Packet[P.Message],
+) error {
+	pane, ok := c.findSessionPane(request.Session)
+	if !ok {
+		return fmt.Errorf("could not find the pane cy pick was run in")
+	}
+
+	client, ok := c.findPaneClient(pane.Id())
+	if !ok {
+		return fmt.Errorf("no client is using pane %d", pane.Id())
+	}
+
+	ctx, cancel := context.WithCancel(conn.Ctx())
+	defer cancel()
+	go func() {
+		select {
+		case <-client.Ctx().Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	outerLayers := client.OuterLayers()
+	state := outerLayers.State()
+	cursor := state.Cursor
+	result := make(chan interface{})
+
+	settings := []fuzzy.Setting{
+		fuzzy.WithResult(result),
+		fuzzy.WithPrompt(request.Prompt),
+		fuzzy.WithInline(geom.Vec2{R: cursor.Y, C: cursor.X}),
+		fuzzy.WithStream(readOptions(ctx, events)),
+	}
+
+	if request.Multi {
+		settings = append(settings, fuzzy.WithMulti)
+	}
+
+	if request.Reverse {
+		settings = append(settings, fuzzy.WithReverse)
+	}
+
+	animated, _ := client.Params().Get(cyParams.ParamAnimate)
+	if value, ok := animated.(bool); !ok || value {
+		settings = append(settings, fuzzy.WithAnimation(state.Image))
+	}
+
+	finder := fuzzy.NewFuzzy(ctx, nil, settings...)
+	outerLayers.NewLayer(
+		finder.Ctx(),
+		finder,
+		screen.PositionTop,
+		screen.WithInteractive,
+		screen.WithOpaque,
+	)
+
+	var chosen []string
+	select {
+	case match := <-result:
+		switch match := match.(type) {
+		case string:
+			chosen = []string{match}
+		case []interface{}:
+			for _, option := range match {
+				if line, ok := option.(string); ok {
+					chosen = append(chosen, line)
+				}
+			}
+		}
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return conn.Send(P.ChosenMessage{
+		Options: chosen,
+	})
+}
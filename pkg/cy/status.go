@@ -0,0 +1,294 @@
+package cy
+
+import (
+	_ "embed"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cfoust/cy/pkg/cy/api"
+	cyParams "github.com/cfoust/cy/pkg/cy/params"
+	"github.com/cfoust/cy/pkg/janet"
+	"github.com/cfoust/cy/pkg/mux/screen/replayable"
+	"github.com/cfoust/cy/pkg/mux/screen/status"
+	"github.com/cfoust/cy/pkg/mux/screen/tree"
+)
+
+// How often we render the status line even if nothing happened, which
+// keeps things like clocks up to date.
+const statusInterval = time.Second
+
+// alert is a set of flags describing the events that happened in a pane
+// while no client was attached to it.
+type alert int
+
+const (
+	alertBell alert = 1 << iota
+	alertActivity
+	alertSilence
+)
+
+// raiseAlert marks that `flag` happened in `node` unless a client is
+// attached to it.
+func (c *Cy) raiseAlert(node tree.Node, flag alert) {
+	c.RLock()
+	clients := c.clients
+	c.RUnlock()
+
+	for _, client := range clients {
+		if attached := client.Node(); attached != nil && attached.Id() == node.Id() {
+			return
+		}
+	}
+
+	c.Lock()
+	c.alerts[node.Id()] |= flag
+	c.Unlock()
+
+	c.refreshStatus()
+}
+
+// clearAlerts forgets about everything that happened in the node `id`,
+// which we do when a client visits it.
+func (c *Cy) clearAlerts(id tree.NodeID) {
+	c.Lock()
+	_, ok := c.alerts[id]
+	delete(c.alerts, id)
+	c.Unlock()
+
+	if ok {
+		c.refreshStatus()
+	}
+}
+
+// getAlerts returns the IDs of the nodes that have raised `flag`, in
+// ascending order.
+func (c *Cy) getAlerts(flag alert) []tree.NodeID {
+	c.RLock()
+	ids := make([]tree.NodeID, 0)
+	for id, flags := range c.alerts {
+		if flags&flag == 0 {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	c.RUnlock()
+
+	existing := ids[:0]
+	for _, id := range ids {
+		if _, ok := c.tree.NodeById(id); !ok {
+			continue
+		}
+		existing = append(existing, id)
+	}
+
+	sort.Slice(existing, func(i, j int) bool {
+		return existing[i] < existing[j]
+	})
+	return existing
+}
+
+// refreshStatus re-renders the status line of every client.
+func (c *Cy) refreshStatus() {
+	c.RLock()
+	clients := c.clients
+	c.RUnlock()
+
+	for _, client := range clients {
+		client.refreshStatus()
+	}
+}
+
+func (c *Cy) getStatusRender() *janet.Function {
+	c.RLock()
+	defer c.RUnlock()
+	return c.statusRender
+}
+
+// StatusPane describes the pane a client is attached to.
+type StatusPane struct {
+	Id   tree.NodeID
+	Name string
+	// The path to the pane in the node tree, e.g. /shells/foo
+	Path string
+	// The working directory of the pane's process
+	Cwd string
+}
+
+// StatusInfo is passed to the function that renders the status line.
+type StatusInfo struct {
+	Pane StatusPane
+	// The key table the client is in, if any
+	KeyTable string
+	// The register the client is recording a macro into, if any
+	Macro string
+	// Whether the client's pane is in replay mode
+	Replay bool
+	// The current time, in seconds since the Unix epoch
+	Time int
+	// The panes that rang the bell, produced output, or went silent
+	// while no client was attached to them
+	Bell     []tree.NodeID
+	Activity []tree.NodeID
+	Silence  []tree.NodeID
+}
+
+func (c *Client) getStatusInfo() StatusInfo {
+	info := StatusInfo{
+		KeyTable: c.KeyTable(),
+		Macro:    c.MacroRegister(),
+		Time:     int(time.Now().Unix()),
+		Bell:     c.cy.getAlerts(alertBell),
+		Activity: c.cy.getAlerts(alertActivity),
+		Silence:  c.cy.getAlerts(alertSilence),
+	}
+
+	node := c.Node()
+	if node == nil {
+		return info
+	}
+
+	info.Pane.Id = node.Id()
+	info.Pane.Name = node.Name()
+	if path := (&api.TreeModule{Tree: c.cy.tree}).Path(node.Id()); path != nil {
+		info.Pane.Path = *path
+	}
+	if cwd, err := (&api.Cmd{Tree: c.cy.tree}).Path(node.Id()); err == nil {
+		info.Pane.Cwd = *cwd
+	}
+
+	if pane, ok := node.(*tree.Pane); ok {
+		if r, ok := pane.Screen().(*replayable.Replayable); ok {
+			info.Replay = r.IsReplaying()
+		}
+	}
+
+	return info
+}
+
+// renderStatus calls the user's render function and parses the markup it
+// returns.
+func (c *Client) renderStatus(render *janet.Function) (status.Line, error) {
+	result, err := render.CallResult(c.Ctx(), c, c.getStatusInfo())
+	if err != nil {
+		return status.Line{}, err
+	}
+	defer result.Free()
+
+	// Anything other than a string, such as nil, clears the status line
+	var markup string
+	if err := result.Unmarshal(&markup); err != nil {
+		return status.Line{}, nil
+	}
+
+	return status.Parse(markup)
+}
+
+// updateStatus applies the client's parameters to its status line and
+// renders it if it is visible.
+func (c *Client) updateStatus() {
+	value, _ := c.params.Get(cyParams.ParamStatusLine)
+	visible, _ := value.(bool)
+	c.status.SetVisible(visible)
+
+	value, _ = c.params.Get(cyParams.ParamStatusPosition)
+	position := status.PositionBottom
+	if value == "top" {
+		position = status.PositionTop
+	}
+	c.status.SetPosition(position)
+
+	render := c.cy.getStatusRender()
+	if !visible || render == nil {
+		return
+	}
+
+	line, err := c.renderStatus(render)
+	if err != nil {
+		// The render function was replaced, which refreshes the status
+		// line again
+		if c.Ctx().Err() != nil || err == janet.ERROR_FREED {
+			return
+		}
+
+		line = status.Line{
+			Sections: [3][]status.Span{{{
+				Style: status.Style{FG: "1"},
+				Text:  fmt.Sprintf("error: %s", err.Error()),
+			}}},
+		}
+	}
+
+	c.status.SetLine(line)
+}
+
+// refreshStatus asks for the client's status line to be rendered again.
+func (c *Client) refreshStatus() {
+	select {
+	case c.statusUpdates <- struct{}{}:
+	default:
+	}
+}
+
+func (c *Client) pollStatus() {
+	ticker := time.NewTicker(statusInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Ctx().Done():
+			return
+		case <-ticker.C:
+		case <-c.statusUpdates:
+		}
+
+		c.updateStatus()
+	}
+}
+
+//go:embed docs-status.md
+var DOCS_STATUS string
+
+type StatusModule struct {
+	cy *Cy
+}
+
+var _ janet.Documented = (*StatusModule)(nil)
+
+func (s *StatusModule) Documentation() string {
+	return DOCS_STATUS
+}
+
+func (s *StatusModule) SetRender(render *janet.Function) {
+	s.cy.Lock()
+	previous := s.cy.statusRender
+	s.cy.statusRender = render
+	s.cy.Unlock()
+
+	if previous != nil {
+		previous.Free()
+	}
+
+	s.cy.refreshStatus()
+}
+
+func (s *StatusModule) Refresh() {
+	s.cy.refreshStatus()
+}
+
+func (s *StatusModule) Toggle(user interface{}) error {
+	client, ok := user.(*Client)
+	if !ok {
+		return fmt.Errorf("missing client context")
+	}
+
+	value, _ := client.params.Get(cyParams.ParamStatusLine)
+	visible, _ := value.(bool)
+	client.params.Set(cyParams.ParamStatusLine, !visible)
+	client.refreshStatus()
+	return nil
+}
+
+func (s *StatusModule) Escape(text string) string {
+	return status.Escape(text)
+}
@@ -0,0 +1,97 @@
+package cy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cfoust/cy/pkg/janet"
+
+	"github.com/sasha-s/go-deadlock"
+)
+
+// timerRegistry keeps track of the timers created with (cy/after) and
+// (cy/every) so that they can be canceled.
+type timerRegistry struct {
+	deadlock.Mutex
+	nextID int32
+	timers map[int32]context.CancelFunc
+}
+
+func newTimerRegistry() *timerRegistry {
+	return &timerRegistry{
+		timers: make(map[int32]context.CancelFunc),
+	}
+}
+
+func (t *timerRegistry) add(cancel context.CancelFunc) int32 {
+	t.Lock()
+	defer t.Unlock()
+
+	t.nextID++
+	t.timers[t.nextID] = cancel
+	return t.nextID
+}
+
+func (t *timerRegistry) remove(id int32) bool {
+	t.Lock()
+	cancel, ok := t.timers[id]
+	delete(t.timers, id)
+	t.Unlock()
+
+	if ok {
+		cancel()
+	}
+
+	return ok
+}
+
+// runTimer calls `callback` after `delay`. If `repeat` is true, it keeps
+// calling it until the timer is canceled, waiting `delay` after each call
+// finishes.
+func (c *Cy) runTimer(
+	user interface{},
+	delay time.Duration,
+	repeat bool,
+	callback *janet.Function,
+) int32 {
+	ctx, cancel := context.WithCancel(c.Ctx())
+	id := c.timers.add(cancel)
+
+	go func() {
+		defer c.timers.remove(id)
+		// Nothing can call the callback once the timer is done
+		defer callback.Free()
+
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+			}
+
+			// The callback may cancel its own timer, so we don't
+			// use the timer's context here
+			err := callback.CallContext(c.Ctx(), user)
+			if err != nil && err != context.Canceled {
+				c.log.Error().Err(err).Msgf("failed to run timer %d", id)
+				c.toast.Error(fmt.Sprintf(
+					"an error occurred while running timer %d: %s",
+					id,
+					err.Error(),
+				))
+			}
+
+			if !repeat {
+				return
+			}
+
+			timer.Reset(delay)
+		}
+	}()
+
+	return id
+}
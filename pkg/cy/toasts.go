@@ -1,7 +1,14 @@
 package cy
 
 import (
+	"fmt"
+
+	"github.com/cfoust/cy/pkg/cy/params"
+	"github.com/cfoust/cy/pkg/emu"
 	"github.com/cfoust/cy/pkg/mux/screen/toasts"
+	"github.com/cfoust/cy/pkg/mux/screen/tree"
+
+	"github.com/rs/zerolog/log"
 )
 
 func (c *Cy) sendQueuedToasts() {
@@ -52,6 +59,38 @@ func (c *Cy) sendToast(toast toasts.Toast) {
 	c.Unlock()
 }
 
+// handleNotification shows a notification sent by a pane to every client
+// attached to that pane.
+func (c *Cy) handleNotification(id tree.NodeID, notification emu.Notification) {
+	message := notification.Body
+	if notification.Title != "" {
+		message = fmt.Sprintf("%s: %s", notification.Title, notification.Body)
+	}
+
+	c.RLock()
+	clients := c.clients
+	c.RUnlock()
+
+	for _, client := range clients {
+		node := client.Node()
+		if node == nil || node.Id() != id {
+			continue
+		}
+
+		client.toast.Info(message)
+
+		value, _ := client.Params().Get(params.ParamForwardNotifications)
+		if forward, _ := value.(bool); !forward {
+			continue
+		}
+
+		err := client.renderer.Notify(notification)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to forward notification")
+		}
+	}
+}
+
 type ToastLogger struct {
 	send func(toasts.Toast)
 }
@@ -0,0 +1,68 @@
+package cy
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/cfoust/cy/pkg/bind"
+	cyParams "github.com/cfoust/cy/pkg/cy/params"
+	"github.com/cfoust/cy/pkg/mux/screen/whichkey"
+)
+
+// formatKey makes a single step in a key sequence human-readable.
+func formatKey(key string) string {
+	if pattern, ok := strings.CutPrefix(key, "re:"); ok {
+		return "/" + pattern + "/"
+	}
+
+	return key
+}
+
+// showWhichKey displays the possible continuations of the sequence the
+// client has typed so far, if they have enabled it.
+func (c *Client) showWhichKey(event bind.PartialEvent[bind.Action]) {
+	enabled, _ := c.params.Get(cyParams.ParamWhichKey)
+	if value, ok := enabled.(bool); !ok || !value || len(event.Matches) == 0 {
+		c.whichKey.Send(whichkey.Hide{})
+		return
+	}
+
+	delay, _ := getMilliseconds(c.params, cyParams.ParamWhichKeyDelay)
+
+	// Matches are ordered from the most specific scope to the least, so
+	// the first hint for a sequence is the one that will fire
+	seen := make(map[string]struct{})
+	hints := make([]whichkey.Hint, 0, len(event.Matches))
+	for _, match := range event.Matches {
+		id := strings.Join(match.Bind.Path, "\x00")
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+
+		keys := make([]string, len(match.Bind.Path))
+		for i, key := range match.Bind.Path {
+			keys[i] = formatKey(key)
+		}
+
+		hints = append(hints, whichkey.Hint{
+			Keys:        keys,
+			Description: match.Bind.Value.Description,
+		})
+	}
+
+	sort.SliceStable(hints, func(i, j int) bool {
+		return strings.Join(hints[i].Keys, " ") < strings.Join(hints[j].Keys, " ")
+	})
+
+	prefix := make([]string, len(event.Prefix))
+	for i, key := range event.Prefix {
+		prefix[i] = formatKey(key)
+	}
+
+	c.whichKey.Send(whichkey.Show{
+		Prefix: prefix,
+		Hints:  hints,
+		Delay:  delay,
+	})
+}
@@ -0,0 +1,115 @@
+package emu
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// styleMask contains the glyph modes that change how it looks.
+const styleMask = attrReverse | attrUnderline | attrBold | attrItalic | attrBlink
+
+// isBlank reports whether the glyph is a space that has not been styled.
+func (g Glyph) isBlank() bool {
+	return g.Char == ' ' && g.FG == DefaultFG && g.BG == DefaultBG && g.Mode&styleMask == 0
+}
+
+// cells returns the glyphs in the line that a user would actually see,
+// which excludes the placeholder cells after wide characters and any
+// trailing blank cells.
+func (l Line) cells() []Glyph {
+	end := len(l)
+	for end > 0 && l[end-1].isBlank() {
+		end--
+	}
+
+	cells := make([]Glyph, 0, end)
+	for i := 0; i < end; i++ {
+		cells = append(cells, l[i])
+		if runewidth.RuneWidth(l[i].Char) == 2 {
+			i++
+		}
+	}
+
+	return cells
+}
+
+// Text returns the text content of the line without trailing whitespace.
+func (l Line) Text() string {
+	var b strings.Builder
+	for _, glyph := range l.cells() {
+		b.WriteRune(glyph.Char)
+	}
+	return b.String()
+}
+
+// sgr returns the SGR sequence that sets the attributes and colors of the
+// glyph from scratch.
+func (g Glyph) sgr() string {
+	fg, bg := g.FG, g.BG
+	params := []string{"0"}
+
+	// The colors of reversed glyphs have already been swapped
+	if g.Mode&attrReverse != 0 {
+		fg, bg = bg, fg
+		params = append(params, "7")
+	}
+	if g.Mode&attrBold != 0 {
+		params = append(params, "1")
+	}
+	if g.Mode&attrItalic != 0 {
+		params = append(params, "3")
+	}
+	if g.Mode&attrUnderline != 0 {
+		params = append(params, "4")
+	}
+	if g.Mode&attrBlink != 0 {
+		params = append(params, "5")
+	}
+
+	if param := colorParam(fg, 38); param != "" {
+		params = append(params, param)
+	}
+	if param := colorParam(bg, 48); param != "" {
+		params = append(params, param)
+	}
+
+	return fmt.Sprintf("\x1b[%sm", strings.Join(params, ";"))
+}
+
+// colorParam returns the SGR parameter that sets the foreground (base 38)
+// or background (base 48) to `color`.
+func colorParam(color Color, base int) string {
+	switch {
+	case color >= DefaultFG:
+		return ""
+	case color < 256:
+		return fmt.Sprintf("%d;5;%d", base, color)
+	default:
+		r, g, b := color>>16, (color>>8)&0xff, color&0xff
+		return fmt.Sprintf("%d;2;%d;%d;%d", base, r, g, b)
+	}
+}
+
+// ANSI returns the text content of the line without trailing whitespace,
+// using SGR sequences to reproduce the colors and attributes of each cell.
+func (l Line) ANSI() string {
+	var b strings.Builder
+
+	var last *Glyph
+	for _, glyph := range l.cells() {
+		glyph := glyph
+		if last == nil || last.FG != glyph.FG || last.BG != glyph.BG || last.Mode&styleMask != glyph.Mode&styleMask {
+			b.WriteString(glyph.sgr())
+		}
+		last = &glyph
+		b.WriteRune(glyph.Char)
+	}
+
+	if last != nil {
+		b.WriteString("\x1b[0m")
+	}
+
+	return b.String()
+}
@@ -49,9 +49,32 @@ const (
 	ModeFocus
 	ModeMouseX10
 	ModeMouseMany
+	ModeBracketedPaste
 	ModeMouseMask = ModeMouseButton | ModeMouseMotion | ModeMouseX10 | ModeMouseMany
 )
 
+// KeyFlag represents the progressive enhancement flags of the kitty keyboard
+// protocol. See https://sw.kovidgoyal.net/kitty/keyboard-protocol/
+type KeyFlag uint32
+
+// Kitty keyboard protocol flags
+const (
+	KeyDisambiguate KeyFlag = 1 << iota
+	KeyReportEvents
+	KeyReportAlternates
+	KeyReportAll
+	KeyReportText
+	KeyFlagMask = KeyDisambiguate | KeyReportEvents | KeyReportAlternates | KeyReportAll | KeyReportText
+)
+
+// Notification is a desktop notification sent by the application using
+// OSC 9 or OSC 777.
+type Notification struct {
+	// Title is empty for OSC 9, which only supports a message body.
+	Title string
+	Body  string
+}
+
 // ChangeFlag represents possible state changes of the terminal.
 type ChangeFlag uint32
 
@@ -90,15 +113,19 @@ func (l Line) String() (str string) {
 	return str
 }
 
+// CursorStyle is the shape of the cursor as set by DECSCUSR. Its value is
+// the same as the parameter used in the DECSCUSR sequence.
 type CursorStyle int
 
 const (
-	CursorStyleBlock CursorStyle = iota
+	// CursorStyleDefault is whatever the user's terminal uses by default.
+	CursorStyleDefault CursorStyle = iota
+	CursorStyleBlinkBlock
 	CursorStyleSteadyBlock
-	CursorStyleUnderline
 	CursorStyleBlinkUnderline
-	CursorStyleBar
+	CursorStyleSteadyUnderline
 	CursorStyleBlinkBar
+	CursorStyleSteadyBar
 )
 
 type Cursor struct {
@@ -106,6 +133,9 @@ type Cursor struct {
 	X, Y  int
 	State uint8
 	Style CursorStyle
+	// Color is the color of the cursor as set by OSC 12. DefaultCursor
+	// means the application has not changed it.
+	Color Color
 }
 
 type Cell struct {
@@ -142,6 +172,22 @@ type View interface {
 	// Title represents the title of the console window.
 	Title() string
 
+	// Directory is the working directory reported by the application
+	// via OSC 7. It is empty if the application never reported one.
+	Directory() string
+
+	// DirectoryHost is the host on which Directory is located, such as
+	// when the application runs over SSH. It is empty for this machine.
+	DirectoryHost() string
+
+	// Bell reports whether the application rang the bell since the last
+	// call to Bell. Calling it clears the bell.
+	Bell() bool
+
+	// Notifications returns the notifications the application sent since
+	// the last call to Notifications and clears them.
+	Notifications() []Notification
+
 	// Cell returns the glyph containing the character code, foreground color, and
 	// background color at position (x, y) relative to the top left of the terminal.
 	Cell(x, y int) Glyph
@@ -152,24 +198,37 @@ type View interface {
 	// CursorVisible returns the visible state of the cursor.
 	CursorVisible() bool
 
+	// KeyFlags returns the kitty keyboard protocol flags the application
+	// running in the terminal has requested. Zero means that the
+	// application expects legacy key encoding.
+	KeyFlags() KeyFlag
+
 	// Screen gets all of the lines on the screen.
 	Screen() []Line
 
 	// History returns the scrollback buffer.
 	History() []Line
 
+	// RecentHistory returns a copy of up to `n` of the most recent lines
+	// in the scrollback buffer.
+	RecentHistory(n int) []Line
+
 	// ToggleHistory allows you to enable and disable saving lines to the
 	// scrollback buffer.
 	EnableHistory(enabled bool)
 
+	// ClearHistory removes all lines from the scrollback buffer.
+	ClearHistory()
+
 	Changes() *Dirty
 }
 
 type TerminalOption func(*TerminalInfo)
 
 type TerminalInfo struct {
-	w          io.Writer
-	cols, rows int
+	w            io.Writer
+	cols, rows   int
+	historyLimit int
 }
 
 func WithWriter(w io.Writer) TerminalOption {
@@ -185,6 +244,15 @@ func WithSize(size geom.Vec2) TerminalOption {
 	}
 }
 
+// WithHistoryLimit caps the number of lines kept in the scrollback buffer.
+// The oldest lines are discarded first. A limit of zero (the default) means
+// the scrollback buffer is unbounded.
+func WithHistoryLimit(limit int) TerminalOption {
+	return func(info *TerminalInfo) {
+		info.historyLimit = limit
+	}
+}
+
 // New returns a new virtual terminal emulator.
 func New(opts ...TerminalOption) Terminal {
 	info := TerminalInfo{
@@ -195,5 +263,7 @@ func New(opts ...TerminalOption) Terminal {
 	for _, opt := range opts {
 		opt(&info)
 	}
-	return newTerminal(info)
+	t := newTerminal(info)
+	t.historyLimit = max(info.historyLimit, 0)
+	return t
 }
@@ -1,6 +1,7 @@
 package emu
 
 import (
+	"bytes"
 	"fmt"
 
 	"github.com/mattn/go-runewidth"
@@ -38,8 +39,7 @@ func (t *State) Execute(b byte) {
 		t.newline(t.mode&ModeCRLF != 0)
 	// BEL
 	case '\a':
-		// TODO: emit sound
-		// TODO: window alert if not focused
+		t.bell = true
 	}
 }
 
@@ -59,9 +59,12 @@ func (t *State) Hook(params []int64, intermediates []byte, ignore bool, r rune)
 }
 
 func (t *State) OscDispatch(params [][]byte, bellTerminated bool) {
-	// TODO(cfoust): 08/10/23 do we care about operating system commands?
-	// I can only find one reference to them here: https://vt100.net/docs/vt510-rm/chapter4.html
-	//fmt.Printf("[OscDispatch] params=%v, bellTerminated=%v\n", params, bellTerminated)
+	// go-vte splits the OSC string on semicolons, but handleSTR does its
+	// own parsing
+	t.str.reset()
+	t.str.typ = ']'
+	t.str.buf = []rune(string(bytes.Join(params, []byte(";"))))
+	t.handleSTR()
 }
 
 func (t *State) CsiDispatch(params []int64, intermediates []byte, ignore bool, r rune) {
@@ -199,21 +202,25 @@ func (t *State) CsiDispatch(params []int64, intermediates []byte, ignore bool, r
 		}
 	case 's': // DECSC - save cursor position (ANSI.SYS)
 		t.saveCursor()
-	case 'u': // DECRC - restore cursor position (ANSI.SYS)
-		t.restoreCursor()
+	case 'u':
+		// The kitty keyboard protocol reuses this final byte, see
+		// https://sw.kovidgoyal.net/kitty/keyboard-protocol/
+		switch c.intermediate(0, 0) {
+		case '>': // push keyboard flags
+			t.pushKeyFlags(KeyFlag(c.arg(0, 0)))
+		case '<': // pop keyboard flags
+			t.popKeyFlags(c.arg(0, 1))
+		case '=': // set keyboard flags
+			t.setKeyFlags(KeyFlag(c.arg(0, 0)), c.arg(1, 1))
+		case '?': // query keyboard flags
+			t.w.Write([]byte(fmt.Sprintf("\033[?%du", t.keyFlags())))
+		default: // DECRC - restore cursor position (ANSI.SYS)
+			t.restoreCursor()
+		}
 	case 'q': // DECSCUSR - set cursor style
-		style := CursorStyleBlock
-		switch c.arg(0, 0) {
-		case 2:
-			style = CursorStyleSteadyBlock
-		case 3:
-			style = CursorStyleUnderline
-		case 4:
-			style = CursorStyleBlinkUnderline
-		case 5:
-			style = CursorStyleBar
-		case 6:
-			style = CursorStyleBlinkBar
+		style := CursorStyle(c.arg(0, 0))
+		if style > CursorStyleSteadyBar {
+			goto unknown
 		}
 		t.cur.Style = style
 	case 't': // XTWINOPS - window manipulation (ignored)
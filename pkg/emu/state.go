@@ -45,12 +45,26 @@ type State struct {
 	numlock       bool
 	tabs          []bool
 	title         string
+	directory     string
+	directoryHost string
 	colorOverride map[Color]Color
 
+	// whether a BEL was received since the last call to Bell()
+	bell bool
+	// notifications received since the last call to Notifications()
+	notifications []Notification
+
+	// the kitty keyboard protocol flag stacks for the main and alternate
+	// screens, which are tracked separately
+	keyFlagStack, altKeyFlagStack []KeyFlag
+
 	dirty *Dirty
 
 	// whether scrollingup should send lines to the scrollback buffer
 	disableHistory bool
+	// the maximum number of lines in the scrollback buffer, or zero if
+	// there is no limit
+	historyLimit int
 
 	parser *vtparser.Parser
 }
@@ -104,11 +118,16 @@ func (t *State) Cell(x, y int) Glyph {
 	return cell
 }
 
-// Cursor returns the current position of the cursor.
+// Cursor returns the current position and appearance of the cursor.
 func (t *State) Cursor() Cursor {
 	t.RLock()
 	defer t.RUnlock()
-	return t.cur
+	cur := t.cur
+	cur.Color = DefaultCursor
+	if color, ok := t.colorOverride[DefaultCursor]; ok {
+		cur.Color = color
+	}
+	return cur
 }
 
 // CursorVisible returns the visible state of the cursor.
@@ -118,6 +137,49 @@ func (t *State) CursorVisible() bool {
 	return t.mode&ModeHide == 0
 }
 
+// KeyFlags returns the currently active kitty keyboard protocol flags.
+func (t *State) KeyFlags() KeyFlag {
+	t.RLock()
+	defer t.RUnlock()
+	return t.keyFlags()
+}
+
+// Directory returns the working directory most recently reported by the
+// application using OSC 7, if any.
+func (t *State) Directory() string {
+	t.RLock()
+	defer t.RUnlock()
+	return t.directory
+}
+
+// DirectoryHost returns the host on which the directory returned by
+// Directory is located. It is empty if the directory is on this machine.
+func (t *State) DirectoryHost() string {
+	t.RLock()
+	defer t.RUnlock()
+	return t.directoryHost
+}
+
+// Bell reports whether the application rang the bell (BEL) since the last
+// time Bell was called.
+func (t *State) Bell() bool {
+	t.Lock()
+	defer t.Unlock()
+	rang := t.bell
+	t.bell = false
+	return rang
+}
+
+// Notifications returns the notifications the application sent using OSC 9
+// or OSC 777 since the last time Notifications was called.
+func (t *State) Notifications() []Notification {
+	t.Lock()
+	defer t.Unlock()
+	notifications := t.notifications
+	t.notifications = nil
+	return notifications
+}
+
 // Mode returns the current terminal mode.
 func (t *State) Mode() ModeFlag {
 	t.RLock()
@@ -139,12 +201,62 @@ func (t *State) ChangeMask() ChangeFlag {
 }
 */
 
+// maxKeyFlags is the maximum size of the kitty keyboard flag stack. The
+// protocol specification recommends evicting the oldest entries once the
+// stack grows beyond some reasonable limit.
+const maxKeyFlags = 16
+
+func (t *State) keyFlags() KeyFlag {
+	if len(t.keyFlagStack) == 0 {
+		return 0
+	}
+	return t.keyFlagStack[len(t.keyFlagStack)-1]
+}
+
+func (t *State) pushKeyFlags(flags KeyFlag) {
+	t.keyFlagStack = append(t.keyFlagStack, flags&KeyFlagMask)
+	if len(t.keyFlagStack) > maxKeyFlags {
+		t.keyFlagStack = t.keyFlagStack[1:]
+	}
+}
+
+func (t *State) popKeyFlags(n int) {
+	if n >= len(t.keyFlagStack) {
+		t.keyFlagStack = nil
+		return
+	}
+	t.keyFlagStack = t.keyFlagStack[:len(t.keyFlagStack)-n]
+}
+
+// setKeyFlags modifies the flags at the top of the stack. Per the
+// specification, mode 1 replaces the flags, mode 2 sets the given bits, and
+// mode 3 clears them.
+func (t *State) setKeyFlags(flags KeyFlag, mode int) {
+	flags &= KeyFlagMask
+	current := t.keyFlags()
+	switch mode {
+	case 2:
+		flags = current | flags
+	case 3:
+		flags = current &^ flags
+	}
+
+	if len(t.keyFlagStack) == 0 {
+		t.keyFlagStack = append(t.keyFlagStack, flags)
+		return
+	}
+	t.keyFlagStack[len(t.keyFlagStack)-1] = flags
+}
+
 func (t *State) saveCursor() {
 	t.curSaved = t.cur
 }
 
 func (t *State) restoreCursor() {
+	// DECRC does not restore the cursor's style
+	style := t.cur.Style
 	t.cur = t.curSaved
+	t.cur.Style = style
 	t.moveTo(t.cur.X, t.cur.Y)
 }
 
@@ -316,6 +428,7 @@ func (t *State) resize(cols, rows int) bool {
 			t.cur.X,
 		)
 		t.history = history
+		t.trimHistory()
 		for i := range lines {
 			copy(t.lines[i], lines[i])
 		}
@@ -402,6 +515,7 @@ func (t *State) moveTo(x, y int) {
 func (t *State) swapScreen() {
 	t.lines, t.altLines = t.altLines, t.lines
 	t.history, t.altHistory = t.altHistory, t.history
+	t.keyFlagStack, t.altKeyFlagStack = t.altKeyFlagStack, t.keyFlagStack
 	t.mode ^= ModeAltScreen
 	t.dirtyAll()
 }
@@ -461,6 +575,28 @@ func (t *State) EnableHistory(enabled bool) {
 	t.disableHistory = !enabled
 }
 
+func (t *State) ClearHistory() {
+	t.Lock()
+	defer t.Unlock()
+	t.history = nil
+	t.dirtyAll()
+}
+
+// trimHistory discards the oldest lines in the scrollback buffer that
+// exceed the history limit. To avoid copying the whole buffer for every
+// line that scrolls off the screen, we let it grow to half again the limit
+// before trimming it; History only ever returns the newest lines.
+func (t *State) trimHistory() {
+	if t.historyLimit == 0 || len(t.history) < t.historyLimit+t.historyLimit/2 {
+		return
+	}
+
+	// Copy the lines we keep so the old backing array can be collected
+	history := make([]Line, t.historyLimit, t.historyLimit+t.historyLimit/2)
+	copy(history, t.history[len(t.history)-t.historyLimit:])
+	t.history = history
+}
+
 func (t *State) scrollDown(orig, n int) {
 	n = clamp(n, 0, t.bottom-orig+1)
 
@@ -506,6 +642,7 @@ func (t *State) scrollUp(orig, n int) {
 		for i := 0; i < n; i++ {
 			t.history = append(t.history, copyLine(t.lines[i]))
 		}
+		t.trimHistory()
 	}
 
 	t.clear(0, orig, t.cols-1, orig+n-1)
@@ -586,6 +723,8 @@ func (t *State) setMode(priv bool, set bool, args []int) {
 				t.modMode(set, ModeMouseSgr)
 			case 1034:
 				t.modMode(set, Mode8bit)
+			case 2004: // bracketed paste
+				t.modMode(set, ModeBracketedPaste)
 			case 1049, // = 1047 and 1048
 				47, 1047:
 				alt := t.mode&ModeAltScreen != 0
@@ -817,9 +956,29 @@ func (t *State) Screen() []Line {
 func (t *State) History() []Line {
 	t.Lock()
 	defer t.Unlock()
+	if t.historyLimit > 0 && len(t.history) > t.historyLimit {
+		return t.history[len(t.history)-t.historyLimit:]
+	}
 	return t.history
 }
 
+func (t *State) RecentHistory(n int) []Line {
+	t.Lock()
+	defer t.Unlock()
+
+	history := t.history
+	if t.historyLimit > 0 && len(history) > t.historyLimit {
+		history = history[len(history)-t.historyLimit:]
+	}
+	n = max(0, min(n, len(history)))
+
+	lines := make([]Line, 0, n)
+	for _, line := range history[len(history)-n:] {
+		lines = append(lines, append(Line(nil), line...))
+	}
+	return lines
+}
+
 func (t *State) String() string {
 	t.Lock()
 	defer t.Unlock()
@@ -3,6 +3,8 @@ package emu
 import (
 	"fmt"
 	"math"
+	"net/url"
+	"os"
 	"regexp"
 	"strconv"
 	"strings"
@@ -69,6 +71,38 @@ func (t *State) handleSTR() {
 			if title != "" {
 				t.setTitle(title)
 			}
+		case 7: // current working directory
+			if len(s.args) < 2 {
+				break
+			}
+
+			// The URL may contain semicolons
+			location := strings.Join(s.args[1:], ";")
+			if err := t.setDirectory(location); err != nil {
+				t.logf("invalid working directory: %s\n", location)
+			}
+		case 9: // notification
+			if len(s.args) < 2 {
+				break
+			}
+
+			// ConEmu uses OSC 9 followed by a number for other purposes,
+			// such as OSC 9;4 for reporting progress
+			if _, err := strconv.Atoi(s.args[1]); err == nil {
+				break
+			}
+
+			t.notify("", strings.Join(s.args[1:], ";"))
+		case 777: // notification with a title
+			if len(s.args) < 3 || s.args[1] != "notify" {
+				break
+			}
+
+			body := ""
+			if len(s.args) > 3 {
+				body = strings.Join(s.args[3:], ";")
+			}
+			t.notify(s.args[2], body)
 		case 10:
 			if len(s.args) < 2 {
 				break
@@ -93,24 +127,27 @@ func (t *State) handleSTR() {
 			if p != nil && *p == "?" {
 				t.oscColorResponse(int(DefaultBG), 11)
 			} else if err := t.setColorName(int(DefaultBG), p); err != nil {
-				t.logf("invalid cursor color: %s\n", maybe(p))
+				t.logf("invalid background color: %s\n", maybe(p))
 			} else {
 				// TODO: redraw
 			}
-		// case 12:
-		// if len(s.args) < 2 {
-		// 	break
-		// }
-
-		// c := s.argString(1, "")
-		// p := &c
-		// if p != nil && *p == "?" {
-		// 	t.oscColorResponse(int(DefaultCursor), 12)
-		// } else if err := t.setColorName(int(DefaultCursor), p); err != nil {
-		// 	t.logf("invalid background color: %s\n", p)
-		// } else {
-		// 	// TODO: redraw
-		// }
+		case 12:
+			if len(s.args) < 2 {
+				break
+			}
+
+			c := s.argString(1, "")
+			p := &c
+			if p != nil && *p == "?" {
+				t.oscColorResponse(int(DefaultCursor), 12)
+			} else if err := t.setColorName(int(DefaultCursor), p); err != nil {
+				t.logf("invalid cursor color: %s\n", maybe(p))
+			} else {
+				t.dirty.markScreen()
+			}
+		case 110, 111, 112: // reset foreground, background, cursor color
+			t.setColorName(int(DefaultFG)+d-110, nil)
+			t.dirty.markScreen()
 		case 4: // color set
 			if len(s.args) < 3 {
 				break
@@ -153,8 +190,52 @@ func (t *State) handleSTR() {
 	}
 }
 
+// setDirectory sets the working directory reported by the application from
+// a file:// URL. Directories on other hosts (for example, when the user is
+// connected over SSH) are stored along with the name of the host.
+func (t *State) setDirectory(location string) error {
+	u, err := url.Parse(location)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme != "file" {
+		return fmt.Errorf("invalid scheme: %s", u.Scheme)
+	}
+
+	host := u.Host
+	if host == "localhost" {
+		host = ""
+	} else if hostname, err := os.Hostname(); err == nil && host == hostname {
+		host = ""
+	}
+
+	t.directory = u.Path
+	t.directoryHost = host
+	return nil
+}
+
+// maxNotifications is the number of unread notifications we keep before
+// dropping the oldest ones.
+const maxNotifications = 16
+
+func (t *State) notify(title, body string) {
+	if title == "" && body == "" {
+		return
+	}
+
+	t.notifications = append(t.notifications, Notification{
+		Title: title,
+		Body:  body,
+	})
+
+	if len(t.notifications) > maxNotifications {
+		t.notifications = t.notifications[1:]
+	}
+}
+
 func (t *State) setColorName(j int, p *string) error {
-	if !between(j, 0, 1<<24) {
+	if !between(j, 0, int(DefaultCursor)) {
 		return fmt.Errorf("invalid color value %d", j)
 	}
 
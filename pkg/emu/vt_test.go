@@ -1,9 +1,12 @@
 package emu
 
 import (
+	"fmt"
 	"io"
 	"strings"
 	"testing"
+
+	"github.com/cfoust/cy/pkg/geom"
 )
 
 func extractStr(term Terminal, x0, x1, row int) string {
@@ -62,3 +65,298 @@ func TestNewline(t *testing.T) {
 		t.Fatal(st.cur.X, st.cur.Y, attr.FG, attr.BG)
 	}
 }
+
+func TestKeyFlags(t *testing.T) {
+	var out strings.Builder
+	term := New(WithWriter(&out))
+
+	write := func(data string) {
+		_, err := term.Write([]byte(data))
+		if err != nil && err != io.EOF {
+			t.Fatal(err)
+		}
+	}
+
+	write("\033[>1u")
+	write("\033[>3u")
+	if flags := term.KeyFlags(); flags != KeyDisambiguate|KeyReportEvents {
+		t.Fatal(flags)
+	}
+
+	write("\033[?u")
+	if out.String() != "\033[?3u" {
+		t.Fatalf("%q", out.String())
+	}
+
+	write("\033[<u")
+	if flags := term.KeyFlags(); flags != KeyDisambiguate {
+		t.Fatal(flags)
+	}
+
+	write("\033[=8;2u")
+	if flags := term.KeyFlags(); flags != KeyDisambiguate|KeyReportAll {
+		t.Fatal(flags)
+	}
+
+	write("\033[=1;3u")
+	if flags := term.KeyFlags(); flags != KeyReportAll {
+		t.Fatal(flags)
+	}
+
+	// The alternate screen has its own stack
+	write("\033[?1049h")
+	if flags := term.KeyFlags(); flags != 0 {
+		t.Fatal(flags)
+	}
+	write("\033[?1049l")
+	if flags := term.KeyFlags(); flags != KeyReportAll {
+		t.Fatal(flags)
+	}
+
+	write("\033[<5u")
+	if flags := term.KeyFlags(); flags != 0 {
+		t.Fatal(flags)
+	}
+}
+
+func TestCursorAppearance(t *testing.T) {
+	term := New()
+
+	cur := term.Cursor()
+	if cur.Style != CursorStyleDefault || cur.Color != DefaultCursor {
+		t.Fatal(cur.Style, cur.Color)
+	}
+
+	_, err := term.Write([]byte("\033[5 q\033]12;#ff0000\007"))
+	if err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+
+	cur = term.Cursor()
+	if cur.Style != CursorStyleBlinkBar || cur.Color != Color(0xff0000) {
+		t.Fatal(cur.Style, cur.Color)
+	}
+
+	_, err = term.Write([]byte("\033]112\033\\"))
+	if err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+
+	if cur := term.Cursor(); cur.Color != DefaultCursor {
+		t.Fatal(cur.Color)
+	}
+}
+
+func TestTitle(t *testing.T) {
+	term := New()
+	_, err := term.Write([]byte("\033]2;some title\007"))
+	if err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+
+	if title := term.Title(); title != "some title" {
+		t.Fatal(title)
+	}
+}
+
+func TestDirectory(t *testing.T) {
+	term := New()
+	_, err := term.Write([]byte("\033]7;file://localhost/tmp/some%20dir\033\\"))
+	if err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+
+	if dir := term.Directory(); dir != "/tmp/some dir" {
+		t.Fatal(dir)
+	}
+
+	if host := term.DirectoryHost(); host != "" {
+		t.Fatal(host)
+	}
+
+	// Directories on other machines are stored along with their host
+	_, err = term.Write([]byte("\033]7;file://some-other-machine.invalid/home\007"))
+	if err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+
+	if dir := term.Directory(); dir != "/home" {
+		t.Fatal(dir)
+	}
+
+	if host := term.DirectoryHost(); host != "some-other-machine.invalid" {
+		t.Fatal(host)
+	}
+}
+
+func TestBell(t *testing.T) {
+	term := New()
+	if term.Bell() {
+		t.Fatal("bell rang before any input")
+	}
+
+	// BEL as an OSC terminator is not a bell
+	_, err := term.Write([]byte("\033]2;title\007"))
+	if err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+	if term.Bell() {
+		t.Fatal("OSC terminator rang the bell")
+	}
+
+	_, err = term.Write([]byte("done\007"))
+	if err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+	if !term.Bell() {
+		t.Fatal("bell did not ring")
+	}
+	if term.Bell() {
+		t.Fatal("bell was not cleared")
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	term := New()
+	_, err := term.Write([]byte("\033]9;build finished\007"))
+	if err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+
+	_, err = term.Write([]byte("\033]777;notify;make;done; 0 errors\033\\"))
+	if err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+
+	notifications := term.Notifications()
+	if len(notifications) != 2 {
+		t.Fatal(notifications)
+	}
+
+	if n := notifications[0]; n.Title != "" || n.Body != "build finished" {
+		t.Fatal(n)
+	}
+
+	if n := notifications[1]; n.Title != "make" || n.Body != "done; 0 errors" {
+		t.Fatal(n)
+	}
+
+	if len(term.Notifications()) != 0 {
+		t.Fatal("notifications were not cleared")
+	}
+}
+
+func TestNotificationProgress(t *testing.T) {
+	term := New()
+
+	// ConEmu's progress bar is not a notification
+	_, err := term.Write([]byte("\033]9;4;1;50\007\033]9;4;0\033\\"))
+	if err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+
+	if notifications := term.Notifications(); len(notifications) != 0 {
+		t.Fatal(notifications)
+	}
+}
+
+func TestLineText(t *testing.T) {
+	term := New(WithSize(geom.Vec2{R: 2, C: 10}))
+	_, err := term.Write([]byte("你好 a\r\n\033[1;31mred\033[0m ok"))
+	if err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+
+	lines := term.Screen()
+	if text := lines[0].Text(); text != "你好 a" {
+		t.Fatalf("%q", text)
+	}
+
+	if text := lines[1].ANSI(); text != "\033[0;1;38;5;9mred\033[0m ok\033[0m" {
+		t.Fatalf("%q", text)
+	}
+}
+
+func TestHistoryLimit(t *testing.T) {
+	term := New(WithSize(geom.Vec2{R: 2, C: 10}), WithHistoryLimit(3))
+	for i := 0; i < 10; i++ {
+		_, err := term.Write([]byte(fmt.Sprintf("%d\r\n", i)))
+		if err != nil && err != io.EOF {
+			t.Fatal(err)
+		}
+	}
+
+	history := term.History()
+	if len(history) != 3 {
+		t.Fatal(len(history))
+	}
+
+	if first := history[0][0].Char; first != '6' {
+		t.Fatal(string(first))
+	}
+
+	term.ClearHistory()
+	if len(term.History()) != 0 {
+		t.Fatal(len(term.History()))
+	}
+}
+
+func TestHistoryLimitScroll(t *testing.T) {
+	term := New(WithSize(geom.Vec2{R: 2, C: 10}), WithHistoryLimit(100))
+	for i := 0; i < 1000; i++ {
+		_, err := term.Write([]byte(fmt.Sprintf("%d\r\n", i)))
+		if err != nil && err != io.EOF {
+			t.Fatal(err)
+		}
+
+		if length := len(term.History()); length > 100 {
+			t.Fatal(length)
+		}
+	}
+
+	history := term.History()
+	if len(history) != 100 {
+		t.Fatal(len(history))
+	}
+
+	for i, line := range history {
+		if text := strings.TrimSpace(line.String()); text != fmt.Sprint(899+i) {
+			t.Fatalf("line %d: %q", i, text)
+		}
+	}
+}
+
+func TestRecentHistory(t *testing.T) {
+	term := New(WithSize(geom.Vec2{R: 2, C: 10}))
+	for i := 0; i < 10; i++ {
+		_, err := term.Write([]byte(fmt.Sprintf("%d\r\n", i)))
+		if err != nil && err != io.EOF {
+			t.Fatal(err)
+		}
+	}
+
+	if lines := term.RecentHistory(0); len(lines) != 0 {
+		t.Fatal(len(lines))
+	}
+
+	if lines := term.RecentHistory(100); len(lines) != len(term.History()) {
+		t.Fatal(len(lines))
+	}
+
+	lines := term.RecentHistory(2)
+	if len(lines) != 2 {
+		t.Fatal(len(lines))
+	}
+
+	for i, line := range lines {
+		if text := strings.TrimSpace(line.String()); text != fmt.Sprint(7+i) {
+			t.Fatalf("line %d: %q", i, text)
+		}
+	}
+
+	// The lines are copies
+	lines[0][0].Char = 'x'
+	if history := term.History(); history[len(history)-2][0].Char == 'x' {
+		t.Fatal("history was modified")
+	}
+}
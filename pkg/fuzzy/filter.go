@@ -1,10 +1,14 @@
 package fuzzy
 
 import (
+	"context"
 	"fmt"
+	"runtime"
+	"sort"
+	"sync"
 
-	"github.com/cfoust/cy/pkg/fuzzy/fzf"
 	"github.com/cfoust/cy/pkg/fuzzy/fzf/util"
+	"github.com/cfoust/cy/pkg/geom"
 	"github.com/cfoust/cy/pkg/janet"
 	"github.com/cfoust/cy/pkg/mux/screen/tree"
 )
@@ -23,6 +27,12 @@ type Option struct {
 	Chars   *util.Chars
 	Match   *Match
 	Result  interface{}
+	// added to the option's score whenever it matches a query, which
+	// ranks it above options that match equally well
+	Weight int
+
+	// the position of the option in Fuzzy's list of options
+	index int
 }
 
 type tupleInput struct {
@@ -121,77 +131,230 @@ func UnmarshalOptions(input *janet.Value) (result []Option, err error) {
 			triple.Value,
 		)
 
-		preview := previewInput{}
-		preview.Type = KEYWORD_TEXT
-		err = triple.Preview.Unmarshal(&preview)
-		if err == nil {
-			text := textPreview{}
-			err = preview.Value.Unmarshal(&text)
-			if err != nil {
-				return
-			}
-			option.Preview = text
-			result = append(result, option)
-			continue
+		option.Preview, err = unmarshalPreview(triple.Preview)
+		if err != nil {
+			err = fmt.Errorf("invalid preview for option %d: %s", i, err)
+			return
 		}
 
-		preview.Type = KEYWORD_NODE
-		err = triple.Preview.Unmarshal(&preview)
-		if err == nil {
-			node := nodePreview{}
-			err = preview.Value.Unmarshal(&node)
-			if err != nil {
-				return
-			}
-			option.Preview = node
-			result = append(result, option)
-			continue
-		}
+		result = append(result, option)
+	}
 
-		preview.Type = KEYWORD_REPLAY
-		err = triple.Preview.Unmarshal(&preview)
-		if err == nil {
-			replay := replayPreview{}
-			err = preview.Value.Unmarshal(&replay)
-			if err != nil {
-				return
-			}
-			option.Preview = replay
-			result = append(result, option)
-			continue
-		}
+	return
+}
 
-		err = fmt.Errorf("invalid preview for option %d", i)
+// UnmarshalOption parses a single option, which can take any of the forms
+// of the elements accepted by UnmarshalOptions.
+func UnmarshalOption(input *janet.Value) (option Option, err error) {
+	var str string
+	err = input.Unmarshal(&str)
+	if err == nil {
+		return NewOption(str, str), nil
+	}
+
+	var tuple tupleInput
+	err = input.Unmarshal(&tuple)
+	if err == nil {
+		return NewOption(tuple.Text, tuple.Value), nil
+	}
+
+	var triple tripleInput
+	err = input.Unmarshal(&triple)
+	if err != nil {
+		err = fmt.Errorf("option must be a string or tuple")
 		return
 	}
 
+	option = NewOption(triple.Text, triple.Value)
+	option.Preview, err = unmarshalPreview(triple.Preview)
 	return
 }
 
-func Filter(options []Option, search string) []Option {
-	matches := make([]Option, 0)
-	for _, option := range options {
-		result, pos := fzf.FuzzyMatchV2(
-			true,
-			true,
-			true,
-			option.Chars,
-			[]rune(search),
-			true,
-			nil,
-		)
+func unmarshalPreview(input *janet.Value) (interface{}, error) {
+	preview := previewInput{}
+	preview.Type = KEYWORD_TEXT
+	err := input.Unmarshal(&preview)
+	if err == nil {
+		text := textPreview{}
+		err = preview.Value.Unmarshal(&text)
+		return text, err
+	}
 
-		if result.Score == 0 {
-			continue
-		}
+	preview.Type = KEYWORD_NODE
+	err = input.Unmarshal(&preview)
+	if err == nil {
+		node := nodePreview{}
+		err = preview.Value.Unmarshal(&node)
+		return node, err
+	}
 
-		newOption := option
-		newOption.Match = &Match{
-			Score: result.Score,
-			Index: pos,
-		}
-		matches = append(matches, newOption)
+	preview.Type = KEYWORD_REPLAY
+	err = input.Unmarshal(&preview)
+	if err == nil {
+		replay := replayPreview{}
+		err = preview.Value.Unmarshal(&replay)
+		return replay, err
 	}
 
+	return nil, fmt.Errorf("unknown preview type")
+}
+
+const (
+	// chunkSize is the number of options a worker matches before
+	// checking whether the query was cancelled.
+	chunkSize = 1024
+
+	// The sizes of the scratch space given to each worker, taken from fzf.
+	slab16Size = 100 * 1024
+	slab32Size = 2048
+)
+
+// result is an option that matched a query.
+type result struct {
+	// the index of the option in the full list of options
+	index int
+	match *Match
+}
+
+// queryResult contains all of the options that matched a query, sorted by
+// score.
+type queryResult struct {
+	query   string
+	pattern Pattern
+	// the number of options that existed when the query ran
+	numOptions int
+	results    []result
+}
+
+// options returns the options that matched the query, with their matches
+// filled in.
+func (q *queryResult) options(options []Option) []Option {
+	matches := make([]Option, len(q.results))
+	for i, result := range q.results {
+		matches[i] = options[result.index]
+		matches[i].Match = result.match
+	}
 	return matches
 }
+
+// filter matches `query` against `options` in parallel. If `base` contains
+// the results of a query that the new query narrows (such as when the user
+// types another character), only the options that matched `base` and any
+// options added since `base` ran are considered. `ok` is false if `ctx` was
+// cancelled before matching finished.
+func filter(
+	ctx context.Context,
+	options []Option,
+	base *queryResult,
+	query string,
+) (match *queryResult, ok bool) {
+	pattern := ParsePattern(query)
+	match = &queryResult{
+		query:      query,
+		pattern:    pattern,
+		numOptions: len(options),
+	}
+
+	// candidates are indices into `options`
+	numCandidates := len(options)
+	candidate := func(i int) int { return i }
+	if base != nil && pattern.Narrows(base.pattern) {
+		numPrevious := len(base.results)
+		numCandidates = numPrevious + len(options) - base.numOptions
+		candidate = func(i int) int {
+			if i < numPrevious {
+				return base.results[i].index
+			}
+			return base.numOptions + i - numPrevious
+		}
+	}
+
+	numChunks := (numCandidates + chunkSize - 1) / chunkSize
+	chunks := make(chan int, numChunks)
+	for i := 0; i < numChunks; i++ {
+		chunks <- i
+	}
+	close(chunks)
+
+	chunkResults := make([][]result, numChunks)
+	numWorkers := geom.Min(runtime.GOMAXPROCS(0), numChunks)
+
+	var wg sync.WaitGroup
+	wg.Add(numWorkers)
+	for i := 0; i < numWorkers; i++ {
+		go func() {
+			defer wg.Done()
+			slab := util.MakeSlab(slab16Size, slab32Size)
+			for chunk := range chunks {
+				if ctx.Err() != nil {
+					return
+				}
+
+				start := chunk * chunkSize
+				end := geom.Min(start+chunkSize, numCandidates)
+				var results []result
+				for j := start; j < end; j++ {
+					index := candidate(j)
+					optionMatch, ok := pattern.Match(
+						options[index].Chars,
+						slab,
+					)
+					if !ok {
+						continue
+					}
+					optionMatch.Score += options[index].Weight
+
+					results = append(results, result{
+						index: index,
+						match: optionMatch,
+					})
+				}
+				chunkResults[chunk] = results
+			}
+		}()
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return nil, false
+	}
+
+	for _, results := range chunkResults {
+		match.results = append(match.results, results...)
+	}
+
+	// An empty pattern matches everything, so there's nothing to rank
+	if pattern.IsEmpty() {
+		sort.Slice(match.results, func(i, j int) bool {
+			return match.results[i].index < match.results[j].index
+		})
+		return match, true
+	}
+
+	// Sort by score, then prefer shorter options, then fall back to
+	// the original order
+	sort.Slice(match.results, func(i, j int) bool {
+		a, b := match.results[i], match.results[j]
+		if a.match.Score != b.match.Score {
+			return a.match.Score > b.match.Score
+		}
+
+		aLength := options[a.index].Chars.Length()
+		bLength := options[b.index].Chars.Length()
+		if aLength != bLength {
+			return aLength < bLength
+		}
+
+		return a.index < b.index
+	})
+
+	return match, true
+}
+
+// Filter returns the options that match `search`, which is interpreted
+// using fzf's extended search syntax, sorted by how well they match. See
+// Pattern.
+func Filter(options []Option, search string) []Option {
+	match, _ := filter(context.Background(), options, nil, search)
+	return match.options(options)
+}
@@ -0,0 +1,184 @@
+package fuzzy
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func generateOptions(n int) (options []Option) {
+	for i := 0; i < n; i++ {
+		text := fmt.Sprintf("pkg/module%d/src/file%d.go", i%1000, i)
+		options = append(options, NewOption(text, text))
+	}
+	return
+}
+
+func getTexts(options []Option) (texts []string) {
+	for _, option := range options {
+		texts = append(texts, option.Text)
+	}
+	return
+}
+
+func TestFilterSort(t *testing.T) {
+	options := []Option{
+		NewOption("foo bar baz", nil),
+		NewOption("xfxoxo", nil),
+		NewOption("foo bar", nil),
+		NewOption("foo", nil),
+	}
+
+	require.Equal(
+		t,
+		[]string{"foo", "foo bar", "foo bar baz", "xfxoxo"},
+		getTexts(Filter(options, "foo")),
+	)
+
+	// An empty pattern preserves the original order
+	require.Equal(
+		t,
+		getTexts(options),
+		getTexts(Filter(options, "!")),
+	)
+}
+
+func TestFilterWeight(t *testing.T) {
+	options := []Option{
+		NewOption("foo", nil),
+		NewOption("foo bar", nil),
+	}
+	options[1].Weight = 100
+
+	require.Equal(
+		t,
+		[]string{"foo bar", "foo"},
+		getTexts(Filter(options, "foo")),
+	)
+}
+
+func TestFilterNarrow(t *testing.T) {
+	options := generateOptions(10000)
+
+	for _, test := range []struct {
+		before, after string
+		narrows       bool
+	}{
+		{"mod", "mod1", true},
+		{"mod1", "mod1 file", true},
+		{"'mod", "'mod1", true},
+		{"mod", "'mod1", true},
+		{"^pkg", "^pkg/module1", true},
+		{"go$", "1.go$", true},
+		{"mod", "mod |", true},
+		{"mod", "mod | file", false},
+		{"'mod", "mod1", false},
+		{"!file1", "!file12", false},
+		{"^pkg", "pkg", false},
+	} {
+		before := ParsePattern(test.before)
+		after := ParsePattern(test.after)
+		require.Equal(
+			t,
+			test.narrows,
+			after.Narrows(before),
+			"%s should narrow %s: %t",
+			test.after,
+			test.before,
+			test.narrows,
+		)
+
+		if !test.narrows {
+			continue
+		}
+
+		// Narrowing the previous results must produce exactly
+		// the same results as searching everything
+		base, ok := filter(
+			context.Background(),
+			options,
+			nil,
+			test.before,
+		)
+		require.True(t, ok)
+
+		narrowed, ok := filter(
+			context.Background(),
+			options,
+			base,
+			test.after,
+		)
+		require.True(t, ok)
+
+		require.Equal(
+			t,
+			getTexts(Filter(options, test.after)),
+			getTexts(narrowed.options(options)),
+		)
+	}
+}
+
+func TestFilterAppend(t *testing.T) {
+	options := generateOptions(10000)
+
+	// Options added after the previous query ran should still be
+	// matched when narrowing its results
+	base, ok := filter(
+		context.Background(),
+		options[:5000],
+		nil,
+		"mod1",
+	)
+	require.True(t, ok)
+
+	narrowed, ok := filter(
+		context.Background(),
+		options,
+		base,
+		"mod12",
+	)
+	require.True(t, ok)
+
+	require.Equal(
+		t,
+		getTexts(Filter(options, "mod12")),
+		getTexts(narrowed.options(options)),
+	)
+}
+
+func TestFilterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, ok := filter(ctx, generateOptions(10000), nil, "mod")
+	require.False(t, ok)
+}
+
+func BenchmarkFilter(b *testing.B) {
+	options := generateOptions(100000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Filter(options, "mod12 file5")
+	}
+}
+
+// BenchmarkFilterTyping simulates the user typing a query one character at
+// a time, which lets each query narrow the results of the last.
+func BenchmarkFilterTyping(b *testing.B) {
+	options := generateOptions(100000)
+	query := "mod12 file5"
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var base *queryResult
+		for j := 1; j <= len(query); j++ {
+			base, _ = filter(
+				context.Background(),
+				options,
+				base,
+				query[:j],
+			)
+		}
+	}
+}
@@ -29,6 +29,11 @@ type Fuzzy struct {
 	// Don't allow Fuzzy to quit or the user to choose anything
 	isSticky bool
 
+	// Whether the user can choose more than one option.
+	isMulti bool
+	// the indices of the options the user has chosen
+	chosen map[int]struct{}
+
 	// Whether Fuzzy should display options above or below the input.
 	isUp bool
 
@@ -45,6 +50,25 @@ type Fuzzy struct {
 	selected int
 	pattern  string
 
+	// the results of the most recent query, which later queries can
+	// narrow instead of searching every option again
+	lastQuery *queryResult
+	// cancels the query that is in progress, if any
+	cancelQuery context.CancelFunc
+	// whether a query is in progress
+	isQuerying bool
+	// whether options were added after the query in progress began, which
+	// means it must run again once it finishes
+	isQueryStale bool
+
+	// options that have not been added to `options` yet
+	stream <-chan Option
+	// whether Fuzzy is still receiving options from `stream`
+	isLoading bool
+
+	// where each option was last drawn, used to handle clicks
+	optionRegions []geom.Rect
+
 	// shown before the number of items
 	prompt string
 
@@ -132,9 +156,21 @@ func (f *Fuzzy) Init() taro.Cmd {
 		cmds = append(cmds, taro.WaitScreens(f.Ctx(), f.anim))
 	}
 
+	if f.stream != nil {
+		cmds = append(cmds, f.readStream())
+	}
+
 	return tea.Batch(cmds...)
 }
 
+// addOptions adds options to the end of the list.
+func (f *Fuzzy) addOptions(options []Option) {
+	for _, option := range options {
+		option.index = len(f.options)
+		f.options = append(f.options, option)
+	}
+}
+
 func (f *Fuzzy) getOptions() []Option {
 	if len(f.pattern) > 0 {
 		return f.filtered
@@ -158,15 +194,62 @@ func (f *Fuzzy) emitOption() taro.Cmd {
 		return nil
 	}
 
+	// Commands run concurrently with Update, so the option has to be
+	// read now
+	option := f.getOptions()[f.selected]
 	return func() taro.Msg {
 		return taro.PublishMsg{
 			Msg: SelectedEvent{
-				Option: f.getOptions()[f.selected],
+				Option: option,
 			},
 		}
 	}
 }
 
+// choose returns the selected option to the caller.
+func (f *Fuzzy) choose() (taro.Model, tea.Cmd) {
+	if f.isSticky {
+		return f, nil
+	}
+
+	if f.isMulti {
+		if chosen := f.getChosen(); len(chosen) > 0 {
+			f.result <- chosen
+		} else {
+			f.result <- nil
+		}
+		return f.quit()
+	}
+
+	if f.selected >= 0 && f.selected < len(f.getOptions()) {
+		option := f.getOptions()[f.selected]
+		f.result <- option.Result
+	} else {
+		f.result <- nil
+	}
+	return f.quit()
+}
+
+// move moves the cursor to the next option in the given direction on the
+// screen.
+func (f *Fuzzy) move(upwards bool) (taro.Model, tea.Cmd) {
+	f.haveMoved = true
+	if f.isUp {
+		upwards = !upwards
+	}
+
+	delta := -1
+	if !upwards {
+		delta = 1
+	}
+
+	f.setSelected(f.selected + delta)
+	return f, tea.Batch(
+		f.handlePreview(),
+		f.emitOption(),
+	)
+}
+
 func (f *Fuzzy) Update(msg tea.Msg) (taro.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 	var cmd tea.Cmd
@@ -188,9 +271,25 @@ func (f *Fuzzy) Update(msg tea.Msg) (taro.Model, tea.Cmd) {
 	case taro.ScreenUpdate:
 		return f, taro.WaitScreens(f.Ctx(), f.anim)
 	case matchResult:
-		f.filtered = msg.Filtered
+		// The user has typed something else since this query began
+		if msg.result.query != f.pattern {
+			return f, nil
+		}
+
+		f.isQuerying = false
+		f.lastQuery = msg.result
+		f.filtered = msg.result.options(f.options)
 		f.setSelected(f.selected)
+
+		// Only the options added since this query began need to be
+		// matched
+		if f.isQueryStale {
+			return f, tea.Batch(f.emitOption(), f.queryOptions(f.pattern))
+		}
+
 		return f, f.emitOption()
+	case streamResult:
+		return f.handleStream(msg)
 	case tea.WindowSizeMsg:
 		size := geom.Size{
 			R: msg.Height,
@@ -211,20 +310,39 @@ func (f *Fuzzy) Update(msg tea.Msg) (taro.Model, tea.Cmd) {
 				f.result <- nil
 			}
 			return f.quit()
-		case taro.KeyDown, taro.KeyCtrlJ, taro.KeyUp, taro.KeyCtrlK:
-			f.haveMoved = true
-			upwards := false
-			switch msg.Type {
-			case taro.KeyUp, taro.KeyCtrlK:
-				upwards = true
-			}
-			if f.isUp {
-				upwards = !upwards
+		case taro.KeyDown, taro.KeyCtrlJ:
+			return f.move(false)
+		case taro.KeyUp, taro.KeyCtrlK:
+			return f.move(true)
+		case taro.KeyEnter:
+			return f.choose()
+		}
+
+		if f.isMulti {
+			switch msg.String() {
+			case "tab":
+				return f.toggle(false)
+			case "shift+tab":
+				return f.toggle(true)
+			case "alt+a":
+				f.setAllChosen(true)
+				return f, nil
+			case "alt+d":
+				f.setAllChosen(false)
+				return f, nil
 			}
+		}
+	case taro.MouseMsg:
+		if msg.Type != taro.MousePress || !msg.Down {
+			return f, nil
+		}
 
-			delta := -1
-			if !upwards {
-				delta = 1
+		switch msg.Button {
+		case taro.MouseWheelUp, taro.MouseWheelDown:
+			f.haveMoved = true
+			delta := 1
+			if (msg.Button == taro.MouseWheelUp) != f.isUp {
+				delta = -1
 			}
 
 			f.setSelected(f.selected + delta)
@@ -232,19 +350,22 @@ func (f *Fuzzy) Update(msg tea.Msg) (taro.Model, tea.Cmd) {
 				f.handlePreview(),
 				f.emitOption(),
 			)
-		case taro.KeyEnter:
-			if f.isSticky {
+		case taro.MouseLeft:
+			index, ok := f.optionAt(msg.Vec2)
+			if !ok {
 				return f, nil
 			}
 
-			if f.selected >= 0 && f.selected < len(f.getOptions()) {
-				option := f.getOptions()[f.selected]
-				f.result <- option.Result
-			} else {
-				f.result <- nil
+			f.setSelected(index)
+			if f.isMulti {
+				f.toggleOption(f.getOptions()[index])
+				return f, f.emitOption()
 			}
-			return f.quit()
+
+			return f.choose()
 		}
+
+		return f, nil
 	}
 
 	inputMsg := msg
@@ -258,7 +379,7 @@ func (f *Fuzzy) Update(msg tea.Msg) (taro.Model, tea.Cmd) {
 	value := f.textInput.Value()
 	if f.pattern != value {
 		f.pattern = value
-		cmds = append(cmds, queryOptions(f.options, value))
+		cmds = append(cmds, f.queryOptions(value))
 	}
 
 	return f, tea.Batch(cmds...)
@@ -315,11 +436,11 @@ func WithPrompt(prompt string) Setting {
 	}
 }
 
-func NewFuzzy(
+func newFuzzy(
 	ctx context.Context,
 	options []Option,
 	settings ...Setting,
-) *taro.Program {
+) *Fuzzy {
 	ti := textinput.New()
 	ti.Focus()
 	ti.CharLimit = 20
@@ -329,27 +450,52 @@ func NewFuzzy(
 	f := &Fuzzy{
 		Lifetime:  util.NewLifetime(ctx),
 		render:    taro.NewRenderer(),
-		options:   options,
 		selected:  0,
 		textInput: ti,
 		isUp:      true,
+		chosen:    make(map[int]struct{}),
 	}
+	f.addOptions(options)
 
 	for _, setting := range settings {
 		setting(f.Ctx(), f)
 	}
 
+	return f
+}
+
+func NewFuzzy(
+	ctx context.Context,
+	options []Option,
+	settings ...Setting,
+) *taro.Program {
+	f := newFuzzy(ctx, options, settings...)
 	return taro.New(f.Ctx(), f)
 }
 
 type matchResult struct {
-	Filtered []Option
+	result *queryResult
 }
 
-func queryOptions(options []Option, pattern string) tea.Cmd {
+// queryOptions filters the options in the background, cancelling the
+// previous query if it has not finished yet.
+func (f *Fuzzy) queryOptions(query string) tea.Cmd {
+	if f.cancelQuery != nil {
+		f.cancelQuery()
+	}
+
+	ctx, cancel := context.WithCancel(f.Ctx())
+	f.cancelQuery = cancel
+	f.isQuerying = true
+	f.isQueryStale = false
+
+	options, base := f.options, f.lastQuery
 	return func() tea.Msg {
-		return matchResult{
-			Filtered: Filter(options, pattern),
+		result, ok := filter(ctx, options, base, query)
+		if !ok {
+			return nil
 		}
+
+		return matchResult{result: result}
 	}
 }
@@ -0,0 +1,79 @@
+package fuzzy
+
+import (
+	"context"
+	"sort"
+
+	"github.com/cfoust/cy/pkg/taro"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// WithMulti allows the user to choose more than one option. Instead of a
+// single result, Fuzzy returns a slice containing the results of every
+// chosen option.
+func WithMulti(ctx context.Context, f *Fuzzy) {
+	f.isMulti = true
+}
+
+func (f *Fuzzy) isChosen(option Option) bool {
+	_, ok := f.chosen[option.index]
+	return ok
+}
+
+// toggle chooses the option under the cursor if it was not chosen and
+// unchooses it if it was, then moves the cursor to the next option.
+func (f *Fuzzy) toggle(upwards bool) (taro.Model, tea.Cmd) {
+	options := f.getOptions()
+	if f.selected < 0 || f.selected >= len(options) {
+		return f, nil
+	}
+
+	f.toggleOption(options[f.selected])
+	return f.move(upwards)
+}
+
+func (f *Fuzzy) toggleOption(option Option) {
+	if f.isChosen(option) {
+		delete(f.chosen, option.index)
+		return
+	}
+
+	f.chosen[option.index] = struct{}{}
+}
+
+// setAllChosen chooses or unchooses every option that matches the query.
+func (f *Fuzzy) setAllChosen(chosen bool) {
+	for _, option := range f.getOptions() {
+		if chosen {
+			f.chosen[option.index] = struct{}{}
+		} else {
+			delete(f.chosen, option.index)
+		}
+	}
+}
+
+// getChosen returns the results of the options the user chose in the order
+// they were provided. If the user did not choose any, it returns the
+// result of the option under the cursor.
+func (f *Fuzzy) getChosen() (results []interface{}) {
+	if len(f.chosen) == 0 {
+		options := f.getOptions()
+		if f.selected < 0 || f.selected >= len(options) {
+			return nil
+		}
+
+		return []interface{}{options[f.selected].Result}
+	}
+
+	indices := make([]int, 0, len(f.chosen))
+	for index := range f.chosen {
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+
+	for _, index := range indices {
+		results = append(results, f.options[index].Result)
+	}
+	return
+}
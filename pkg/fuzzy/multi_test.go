@@ -0,0 +1,83 @@
+package fuzzy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cfoust/cy/pkg/taro"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newMulti(t *testing.T) (*Fuzzy, chan interface{}) {
+	var options []Option
+	for _, text := range []string{"a", "b", "c", "d"} {
+		options = append(options, NewOption(text, text))
+	}
+
+	result := make(chan interface{}, 1)
+	f := newFuzzy(
+		context.Background(),
+		options,
+		WithMulti,
+		WithReverse,
+		WithResult(result),
+	)
+	t.Cleanup(f.Cancel)
+	return f, result
+}
+
+func sendKeys(f *Fuzzy, keys ...string) {
+	for _, msg := range taro.KeysToMsg(keys...) {
+		f.Update(msg)
+	}
+}
+
+func TestToggle(t *testing.T) {
+	f, _ := newMulti(t)
+
+	// tab chooses the option under the cursor and moves down
+	sendKeys(f, "tab", "tab")
+	require.Equal(t, 2, f.selected)
+	require.Equal(t, []interface{}{"a", "b"}, f.getChosen())
+
+	// shift+tab does the same, but moves up
+	sendKeys(f, "shift+tab")
+	require.Equal(t, 1, f.selected)
+	require.Equal(t, []interface{}{"a", "b", "c"}, f.getChosen())
+
+	// Toggling a chosen option unchooses it
+	sendKeys(f, "shift+tab")
+	require.Equal(t, 0, f.selected)
+	require.Equal(t, []interface{}{"a", "c"}, f.getChosen())
+
+	sendKeys(f, "alt+a")
+	require.Equal(t, []interface{}{"a", "b", "c", "d"}, f.getChosen())
+
+	sendKeys(f, "alt+d")
+	require.Empty(t, f.chosen)
+
+	// Only the options that match the query are chosen
+	f.pattern = "c"
+	f.Update(matchResult{result: &queryResult{
+		query:   "c",
+		results: []result{{index: 2}},
+	}})
+	sendKeys(f, "alt+a")
+	require.Equal(t, []interface{}{"c"}, f.getChosen())
+}
+
+func TestChooseMulti(t *testing.T) {
+	f, result := newMulti(t)
+
+	// Results are returned in their original order, regardless of the
+	// order in which they were chosen
+	sendKeys(f, "down", "down", "down", "tab", "up", "up", "tab")
+	sendKeys(f, "enter")
+	require.Equal(t, []interface{}{"b", "d"}, <-result)
+
+	// Without any chosen options, the one under the cursor is returned
+	f, result = newMulti(t)
+	sendKeys(f, "down", "enter")
+	require.Equal(t, []interface{}{"b"}, <-result)
+}
@@ -0,0 +1,284 @@
+package fuzzy
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/cfoust/cy/pkg/fuzzy/fzf"
+	"github.com/cfoust/cy/pkg/fuzzy/fzf/util"
+)
+
+type termType int
+
+const (
+	termFuzzy termType = iota
+	termExact
+	termPrefix
+	termSuffix
+	termEqual
+)
+
+var termAlgos = map[termType]fzf.Algo{
+	termFuzzy:  fzf.FuzzyMatchV2,
+	termExact:  fzf.ExactMatchNaive,
+	termPrefix: fzf.PrefixMatch,
+	termSuffix: fzf.SuffixMatch,
+	termEqual:  fzf.EqualMatch,
+}
+
+type term struct {
+	typ termType
+	// whether the term matches options that do NOT contain it
+	inverse bool
+	text    []rune
+}
+
+// termSet is a group of terms separated by |. An option matches the set if
+// it matches any one of them.
+type termSet []term
+
+// Pattern is a query in fzf's extended search syntax. It consists of
+// space-separated terms, all of which must match:
+//
+//	foo    fuzzy match
+//	'foo   exact match
+//	^foo   prefix match
+//	foo$   suffix match
+//	^foo$  equal match
+//	!foo   inverse exact match
+//	!^foo  inverse prefix match
+//	!foo$  inverse suffix match
+//
+// Terms separated by | match if any of them do. A space can be escaped
+// with a backslash.
+type Pattern struct {
+	sets []termSet
+}
+
+// ParsePattern parses `query` in fzf's extended search syntax.
+func ParsePattern(query string) (pattern Pattern) {
+	query = strings.ReplaceAll(query, "\\ ", "\t")
+
+	var set termSet
+	// whether the next term begins a new set
+	switchSet := false
+	afterBar := false
+	for _, token := range strings.Split(query, " ") {
+		if len(token) == 0 {
+			continue
+		}
+
+		typ, inverse := termFuzzy, false
+		text := strings.ReplaceAll(token, "\t", " ")
+
+		if len(set) > 0 && !afterBar && text == "|" {
+			switchSet = false
+			afterBar = true
+			continue
+		}
+		afterBar = false
+
+		if strings.HasPrefix(text, "!") {
+			inverse = true
+			typ = termExact
+			text = text[1:]
+		}
+
+		if text != "$" && strings.HasSuffix(text, "$") {
+			typ = termSuffix
+			text = text[:len(text)-1]
+		}
+
+		if strings.HasPrefix(text, "'") {
+			// Flip exactness
+			if inverse {
+				typ = termFuzzy
+			} else {
+				typ = termExact
+			}
+			text = text[1:]
+		} else if strings.HasPrefix(text, "^") {
+			if typ == termSuffix {
+				typ = termEqual
+			} else {
+				typ = termPrefix
+			}
+			text = text[1:]
+		}
+
+		if len(text) == 0 {
+			continue
+		}
+
+		if switchSet {
+			pattern.sets = append(pattern.sets, set)
+			set = termSet{}
+		}
+
+		set = append(set, term{
+			typ:     typ,
+			inverse: inverse,
+			text:    []rune(text),
+		})
+		switchSet = true
+	}
+
+	if len(set) > 0 {
+		pattern.sets = append(pattern.sets, set)
+	}
+
+	return
+}
+
+// Match checks whether `text` matches every set of terms in the pattern. If
+// it does, it returns the sum of the scores of each set and the indices of
+// all of the characters that matched. `slab` is optional scratch space for
+// the matching algorithms and must not be shared between goroutines.
+func (p Pattern) Match(
+	text *util.Chars,
+	slab *util.Slab,
+) (match *Match, ok bool) {
+	var score int
+	var indices []int
+
+	for _, set := range p.sets {
+		matched := false
+		for _, term := range set {
+			result, pos := termAlgos[term.typ](
+				true,
+				true,
+				true,
+				text,
+				term.text,
+				!term.inverse,
+				slab,
+			)
+
+			if result.Start >= 0 {
+				if term.inverse {
+					continue
+				}
+
+				matched = true
+				score += result.Score
+
+				// Only fuzzy matches report positions; for
+				// the others, we can just use the range
+				if pos != nil {
+					indices = append(indices, *pos...)
+				} else {
+					for i := result.Start; i < result.End; i++ {
+						indices = append(indices, i)
+					}
+				}
+				break
+			}
+
+			if term.inverse {
+				matched = true
+				continue
+			}
+		}
+
+		if !matched {
+			return nil, false
+		}
+	}
+
+	sort.Ints(indices)
+	return &Match{
+		Score: score,
+		Index: &indices,
+	}, true
+}
+
+// implies reports whether every option that matches `t` also matches
+// `other`.
+func (t term) implies(other term) bool {
+	if t.inverse || other.inverse {
+		return t.inverse == other.inverse &&
+			t.typ == other.typ &&
+			string(t.text) == string(other.text)
+	}
+
+	text, otherText := string(t.text), string(other.text)
+	switch other.typ {
+	case termFuzzy:
+		// Any kind of match for `t` is also a fuzzy match for
+		// `t`, which implies a fuzzy match for any subsequence of it
+		return isSubsequence(other.text, t.text)
+	case termExact:
+		return t.typ != termFuzzy && strings.Contains(text, otherText)
+	case termPrefix:
+		return (t.typ == termPrefix || t.typ == termEqual) &&
+			strings.HasPrefix(text, otherText)
+	case termSuffix:
+		return (t.typ == termSuffix || t.typ == termEqual) &&
+			strings.HasSuffix(text, otherText)
+	case termEqual:
+		return t.typ == termEqual && text == otherText
+	}
+
+	return false
+}
+
+// implies reports whether every option that matches `s` also matches
+// `other`.
+func (s termSet) implies(other termSet) bool {
+	for _, term := range s {
+		found := false
+		for _, otherTerm := range other {
+			if term.implies(otherTerm) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Narrows reports whether every option that matches `p` also matches
+// `other`. When it does, the results for `other` can be filtered instead of
+// the full list of options.
+func (p Pattern) Narrows(other Pattern) bool {
+	for _, otherSet := range other.sets {
+		found := false
+		for _, set := range p.sets {
+			if set.implies(otherSet) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsEmpty reports whether the pattern has no terms, in which case it
+// matches everything.
+func (p Pattern) IsEmpty() bool {
+	return len(p.sets) == 0
+}
+
+func isSubsequence(needle, haystack []rune) bool {
+	i := 0
+	for _, char := range haystack {
+		if i == len(needle) {
+			break
+		}
+
+		if needle[i] == char {
+			i++
+		}
+	}
+	return i == len(needle)
+}
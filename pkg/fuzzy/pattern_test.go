@@ -0,0 +1,53 @@
+package fuzzy
+
+import (
+	"testing"
+
+	"github.com/cfoust/cy/pkg/fuzzy/fzf/util"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPattern(t *testing.T) {
+	for _, test := range []struct {
+		query   string
+		text    string
+		matches bool
+	}{
+		{"fb", "foobar", true},
+		{"'oob", "foobar", true},
+		{"'fb", "foobar", false},
+		{"^foo", "foobar", true},
+		{"^bar", "foobar", false},
+		{"bar$", "foobar", true},
+		{"^foobar$", "foobar", true},
+		{"^foo$", "foobar", false},
+		{"!baz", "foobar", true},
+		{"!bar", "foobar", false},
+		{"foo !bar", "foobar", false},
+		{"baz | bar", "foobar", true},
+		{"^baz | ^qux", "foobar", false},
+		{"foo\\ bar", "foo bar", true},
+		{"foo\\ bar", "foobar", false},
+		{"!", "foobar", true},
+	} {
+		chars := util.ToChars([]byte(test.text))
+		_, ok := ParsePattern(test.query).Match(&chars, nil)
+		require.Equal(
+			t,
+			test.matches,
+			ok,
+			"%s should match %s: %t",
+			test.query,
+			test.text,
+			test.matches,
+		)
+	}
+}
+
+func TestPatternIndices(t *testing.T) {
+	chars := util.ToChars([]byte("foobar"))
+	match, ok := ParsePattern("bar$ ^f").Match(&chars, nil)
+	require.True(t, ok)
+	require.Equal(t, []int{0, 3, 4, 5}, *match.Index)
+}
@@ -2,6 +2,7 @@ package stories
 
 import (
 	"context"
+	"time"
 
 	"github.com/cfoust/cy/pkg/fuzzy"
 	"github.com/cfoust/cy/pkg/geom"
@@ -56,6 +57,44 @@ var FullBottom stories.InitFunc = func(ctx context.Context) mux.Screen {
 	return f
 }
 
+var Multi stories.InitFunc = func(ctx context.Context) mux.Screen {
+	f := fuzzy.NewFuzzy(
+		ctx,
+		pokemon,
+		fuzzy.WithInline(geom.Size{}),
+		fuzzy.WithMulti,
+	)
+
+	stories.Send(f, "tab", "down", "tab")
+	return f
+}
+
+var Stream stories.InitFunc = func(ctx context.Context) mux.Screen {
+	// Options trickle in slowly, as they might from a command like `fd`
+	stream := make(chan fuzzy.Option)
+	go func() {
+		defer close(stream)
+		for _, option := range pokemon {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(100 * time.Millisecond):
+				stream <- option
+			}
+		}
+	}()
+
+	f := fuzzy.NewFuzzy(
+		ctx,
+		nil,
+		fuzzy.WithInline(geom.Size{}),
+		fuzzy.WithStream(stream),
+	)
+
+	stories.Send(f, "ar")
+	return f
+}
+
 func init() {
 	config := stories.Config{
 		Size: geom.DEFAULT_SIZE,
@@ -65,4 +104,6 @@ func init() {
 	stories.Register("input/find/search", Search, config)
 	stories.Register("input/find/full-top", FullTop, config)
 	stories.Register("input/find/full-bottom", FullBottom, config)
+	stories.Register("input/find/multi", Multi, config)
+	stories.Register("input/find/stream", Stream, config)
 }
@@ -0,0 +1,98 @@
+package fuzzy
+
+import (
+	"context"
+	"time"
+
+	"github.com/cfoust/cy/pkg/taro"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// streamInterval is how long Fuzzy collects options from a stream before
+// adding them to the list. Batching keeps Fuzzy from re-filtering on every
+// single option.
+const streamInterval = 50 * time.Millisecond
+
+// WithStream adds options to Fuzzy as they are received on `stream`, which
+// lets the user begin searching before all of the options are available.
+// Fuzzy considers loading to be complete once `stream` is closed.
+func WithStream(stream <-chan Option) Setting {
+	return func(ctx context.Context, f *Fuzzy) {
+		f.stream = stream
+		f.isLoading = true
+	}
+}
+
+type streamResult struct {
+	options []Option
+	// whether the stream was closed
+	done bool
+}
+
+// readStream waits for options to arrive on the stream and returns all of
+// the ones received within streamInterval of the first.
+func (f *Fuzzy) readStream() taro.Cmd {
+	ctx, stream := f.Ctx(), f.stream
+	return func() tea.Msg {
+		var options []Option
+		select {
+		case <-ctx.Done():
+			return nil
+		case option, ok := <-stream:
+			if !ok {
+				return streamResult{done: true}
+			}
+			options = append(options, option)
+		}
+
+		timeout := time.After(streamInterval)
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-timeout:
+				return streamResult{options: options}
+			case option, ok := <-stream:
+				if !ok {
+					return streamResult{
+						options: options,
+						done:    true,
+					}
+				}
+				options = append(options, option)
+			}
+		}
+	}
+}
+
+func (f *Fuzzy) handleStream(msg streamResult) (taro.Model, tea.Cmd) {
+	var cmds []tea.Cmd
+	if msg.done {
+		f.isLoading = false
+	} else {
+		cmds = append(cmds, f.readStream())
+	}
+
+	if len(msg.options) == 0 {
+		return f, tea.Batch(cmds...)
+	}
+
+	hadOptions := len(f.getOptions()) > 0
+	f.addOptions(msg.options)
+
+	// Only the new options need to be matched against the query, since
+	// the previous results are still valid. If the query is still running,
+	// we let it finish rather than cancelling it, since a long stream of
+	// options would otherwise keep any query from finishing.
+	if len(f.pattern) > 0 && f.isQuerying {
+		f.isQueryStale = true
+	} else if len(f.pattern) > 0 {
+		cmds = append(cmds, f.queryOptions(f.pattern))
+	} else if !hadOptions {
+		f.setSelected(f.selected)
+		cmds = append(cmds, f.emitOption())
+	}
+
+	return f, tea.Batch(cmds...)
+}
@@ -0,0 +1,73 @@
+package fuzzy
+
+import (
+	"context"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+)
+
+// findMatch runs `cmd` and returns the query result it produces, if any.
+func findMatch(cmd tea.Cmd) *matchResult {
+	if cmd == nil {
+		return nil
+	}
+
+	switch msg := cmd().(type) {
+	case matchResult:
+		return &msg
+	case tea.BatchMsg:
+		for _, cmd := range msg {
+			if match := findMatch(cmd); match != nil {
+				return match
+			}
+		}
+	}
+
+	return nil
+}
+
+func TestStreamQuery(t *testing.T) {
+	stream := make(chan Option)
+	f := newFuzzy(
+		context.Background(),
+		[]Option{
+			NewOption("apple", "apple"),
+			NewOption("banana", "banana"),
+			NewOption("cherry", "cherry"),
+		},
+		WithStream(stream),
+	)
+	t.Cleanup(f.Cancel)
+
+	f.pattern = "a"
+	query := f.queryOptions(f.pattern)
+
+	// Options that arrive while the query runs do not cancel it
+	_, cmd := f.Update(streamResult{
+		options: []Option{NewOption("avocado", "avocado")},
+		done:    true,
+	})
+	require.Nil(t, findMatch(cmd))
+	require.True(t, f.isQueryStale)
+
+	match := findMatch(query)
+	require.NotNil(t, match)
+
+	// Once it finishes, the new options are matched too
+	_, cmd = f.Update(*match)
+	require.Len(t, f.filtered, 2)
+	require.False(t, f.isQueryStale)
+
+	match = findMatch(cmd)
+	require.NotNil(t, match)
+	f.Update(*match)
+	require.False(t, f.isQuerying)
+
+	var matched []string
+	for _, option := range f.filtered {
+		matched = append(matched, option.Text)
+	}
+	require.ElementsMatch(t, []string{"apple", "banana", "avocado"}, matched)
+}
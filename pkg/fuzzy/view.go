@@ -116,7 +116,39 @@ func (f *Fuzzy) renderPreview(state *tty.State) {
 	)
 }
 
-func (f *Fuzzy) renderOptions(common lipgloss.Style) string {
+// setOptionRegions records where each option was drawn so that the user
+// can click on them. `origin` is the top-left corner of the list of options
+// and `heights` contains the height of each option in the order they appear
+// in getOptions().
+func (f *Fuzzy) setOptionRegions(origin geom.Vec2, width int, heights []int) {
+	f.optionRegions = make([]geom.Rect, len(heights))
+	row := origin.R
+	for i := range heights {
+		index := i
+		if f.isUp {
+			index = len(heights) - 1 - i
+		}
+
+		f.optionRegions[index] = geom.Rect{
+			Position: geom.Vec2{R: row, C: origin.C},
+			Size:     geom.Vec2{R: heights[index], C: width},
+		}
+		row += heights[index]
+	}
+}
+
+// optionAt returns the index of the option drawn at `point`, if any.
+func (f *Fuzzy) optionAt(point geom.Vec2) (index int, ok bool) {
+	for i, region := range f.optionRegions {
+		if region.Contains(point) {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+func (f *Fuzzy) renderOptions(common lipgloss.Style) (string, []int) {
 	inactive := common.Copy().
 		Background(lipgloss.Color("#968C83")).
 		Foreground(lipgloss.Color("#20111B"))
@@ -125,15 +157,22 @@ func (f *Fuzzy) renderOptions(common lipgloss.Style) string {
 		Foreground(lipgloss.Color("#20111B"))
 
 	var lines []string
+	var heights []int
 
 	// first, the options
 	for i, match := range f.getOptions() {
+		marker := " "
+		if f.isMulti && f.isChosen(match) {
+			marker = "*"
+		}
+
 		var rendered string
 		if f.selected == i {
-			rendered = active.Render("> " + match.Text)
+			rendered = active.Render(">" + marker + match.Text)
 		} else {
-			rendered = inactive.Render("  " + match.Text)
+			rendered = inactive.Render(" " + marker + match.Text)
 		}
+		heights = append(heights, lipgloss.Height(rendered))
 
 		if f.isUp {
 			lines = append([]string{rendered}, lines...)
@@ -142,7 +181,7 @@ func (f *Fuzzy) renderOptions(common lipgloss.Style) string {
 		}
 	}
 
-	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+	return lipgloss.JoinVertical(lipgloss.Left, lines...), heights
 }
 
 func (f *Fuzzy) renderPrompt(width int) string {
@@ -162,6 +201,12 @@ func (f *Fuzzy) renderPrompt(width int) string {
 		numFiltered,
 		len(f.options),
 	)
+	if f.isLoading {
+		rightSide += "…"
+	}
+	if f.isMulti && len(f.chosen) > 0 {
+		rightSide = fmt.Sprintf("(%d) %s", len(f.chosen), rightSide)
+	}
 
 	return style.Render(
 		lipgloss.JoinHorizontal(
@@ -185,12 +230,13 @@ func (f *Fuzzy) renderInline(state *tty.State) {
 	f.textInput.Cursor.Style = f.render.NewStyle().
 		Background(lipgloss.Color("#E8E3DF"))
 
-	options := f.renderOptions(common)
+	options, heights := f.renderOptions(common)
 	input := common.Render(f.textInput.View())
+	prompt := f.renderPrompt(30)
 	output := lipgloss.JoinVertical(
 		lipgloss.Left,
 		input,
-		f.renderPrompt(30),
+		prompt,
 		options,
 	)
 
@@ -198,7 +244,7 @@ func (f *Fuzzy) renderInline(state *tty.State) {
 		output = lipgloss.JoinVertical(
 			lipgloss.Left,
 			options,
-			f.renderPrompt(30),
+			prompt,
 			input,
 		)
 	}
@@ -213,12 +259,22 @@ func (f *Fuzzy) renderInline(state *tty.State) {
 		C: lipgloss.Width(output),
 	}
 
+	origin := geom.Vec2{
+		R: f.location.R - offset,
+		C: geom.Clamp(f.location.C, 0, f.size.C-size.C),
+	}
+
 	f.render.RenderAt(
 		state.Image,
-		f.location.R-offset,
-		geom.Clamp(f.location.C, 0, f.size.C-size.C),
+		origin.R,
+		origin.C,
 		output,
 	)
+
+	if !f.isUp {
+		origin.R += lipgloss.Height(input) + lipgloss.Height(prompt)
+	}
+	f.setOptionRegions(origin, size.C, heights)
 }
 
 func (f *Fuzzy) View(state *tty.State) {
@@ -247,19 +303,20 @@ func (f *Fuzzy) View(state *tty.State) {
 	f.textInput.Cursor.Style = f.render.NewStyle().
 		Background(lipgloss.Color("#E8E3DF"))
 
-	options := f.renderOptions(common)
+	options, heights := f.renderOptions(common)
 	input := common.Render(f.textInput.View())
+	prompt := f.renderPrompt(size.C)
 	output := lipgloss.JoinVertical(
 		lipgloss.Left,
 		input,
-		f.renderPrompt(size.C),
+		prompt,
 		options,
 	)
 	if f.isUp {
 		output = lipgloss.JoinVertical(
 			lipgloss.Left,
 			options,
-			f.renderPrompt(size.C),
+			prompt,
 			input,
 		)
 	}
@@ -275,4 +332,9 @@ func (f *Fuzzy) View(state *tty.State) {
 		0,
 		output,
 	)
+
+	if !f.isUp {
+		offset += lipgloss.Height(input) + lipgloss.Height(prompt)
+	}
+	f.setOptionRegions(geom.Vec2{R: offset}, size.C, heights)
 }
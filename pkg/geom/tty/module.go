@@ -21,7 +21,10 @@ func (s *State) Clone() *State {
 
 func New(size geom.Vec2) *State {
 	return &State{
-		Image:         image.New(size),
+		Image: image.New(size),
+		Cursor: emu.Cursor{
+			Color: emu.DefaultCursor,
+		},
 		CursorVisible: true,
 	}
 }
@@ -42,6 +42,19 @@ func setColor(info *terminfo.Terminfo, color emu.Color, isBg bool) []byte {
 	return data.Bytes()
 }
 
+// setCursorColor returns the OSC sequence that changes the color of the
+// cursor to `color`.
+func setCursorColor(color emu.Color) []byte {
+	if color == emu.DefaultCursor {
+		return []byte("\x1b]112\x07")
+	}
+
+	// Colors set with OSC 12 are always stored as RGB
+	num := uint32(color)
+	r, g, b := num>>16, (num>>8)&0xff, num&0xff
+	return []byte(fmt.Sprintf("\x1b]12;#%02x%02x%02x\x07", r, g, b))
+}
+
 // Calculate the minimum string to transform `src` in to `dst`.
 func swapImage(
 	info *terminfo.Terminfo,
@@ -110,7 +123,11 @@ func Swap(
 	info.Fprintf(data, terminfo.CursorAddress, srcCursor.Y, srcCursor.X)
 
 	if dstCursor.Style != srcCursor.Style {
-		fmt.Fprintf(data, "\x1b[%d q", int(srcCursor.Style)+1)
+		fmt.Fprintf(data, "\x1b[%d q", int(srcCursor.Style))
+	}
+
+	if dstCursor.Color != srcCursor.Color {
+		data.Write(setCursorColor(srcCursor.Color))
 	}
 
 	// This is wasteful, we shouldn't have to include this on every frame
@@ -15,6 +15,9 @@ const (
 	MessageTypeInput
 	MessageTypeOutput
 	MessageTypeClose
+	MessageTypePick
+	MessageTypeOptions
+	MessageTypeChosen
 )
 
 type Message interface {
@@ -66,3 +69,32 @@ type ErrorMessage struct {
 }
 
 func (i ErrorMessage) Type() MessageType { return MessageTypeError }
+
+// Asks the server to show the fuzzy finder to the client using the pane
+// whose command leads the process session `Session`. This is sent instead
+// of a handshake. The options follow in OptionsMessages.
+type PickMessage struct {
+	Session int
+	Prompt  string
+	Multi   bool
+	Reverse bool
+}
+
+func (i PickMessage) Type() MessageType { return MessageTypePick }
+
+// A batch of options for the fuzzy finder. `Done` is set once there are
+// no more options to send.
+type OptionsMessage struct {
+	Options []string
+	Done    bool
+}
+
+func (i OptionsMessage) Type() MessageType { return MessageTypeOptions }
+
+// The options the user chose in response to a PickMessage. `Options` is
+// empty if they quit without choosing anything.
+type ChosenMessage struct {
+	Options []string
+}
+
+func (i ChosenMessage) Type() MessageType { return MessageTypeChosen }
@@ -33,6 +33,12 @@ func Decode(data []byte) (Message, error) {
 		msg = &SizeMessage{}
 	case MessageTypeClose:
 		msg = &CloseMessage{}
+	case MessageTypePick:
+		msg = &PickMessage{}
+	case MessageTypeOptions:
+		msg = &OptionsMessage{}
+	case MessageTypeChosen:
+		msg = &ChosenMessage{}
 	default:
 		return nil, fmt.Errorf("invalid type: %d", type_)
 	}
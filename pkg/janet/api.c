@@ -1,4 +1,5 @@
 #include <janet.h>
+#include <string.h>
 
 Janet wrap_result_value(Janet value) {
     Janet parts[2] = {
@@ -39,3 +40,22 @@ Janet wrap_keyword(const char *str) {
 int tuple_length(const Janet *t) {
     return janet_tuple_length(t);
 }
+
+// Look up the value for a keyword key in a struct without creating the
+// keyword, which allocates.
+Janet keyword_struct_get(const JanetKV *st, const char *key) {
+    int32_t length = (int32_t) strlen(key);
+    int32_t capacity = janet_struct_capacity(st);
+    for (int32_t i = 0; i < capacity; i++) {
+        const JanetKV *kv = st + i;
+        if (!janet_checktype(kv->key, JANET_KEYWORD)) continue;
+
+        JanetString keyword = janet_unwrap_keyword(kv->key);
+        if (janet_string_length(keyword) == length &&
+            memcmp(keyword, key, length) == 0) {
+            return kv->value;
+        }
+    }
+
+    return janet_wrap_nil();
+}
@@ -7,3 +7,4 @@ const char *cast_janet_string(const uint8_t *jstr);
 const char *_pretty_print(Janet value);
 Janet wrap_keyword(const char *str);
 int tuple_length(const Janet *t);
+Janet keyword_struct_get(const JanetKV *st, const char *key);
@@ -176,3 +176,72 @@ func (v *VM) continueFiber(params Params, fiber Fiber, in *Value) {
 		return
 	}
 }
+
+type ResumeRequest struct {
+	Params
+	Fiber *Fiber
+}
+
+// Resume continues a fiber that is being used as a generator, such as one
+// created with `(generate)` or `(coro)`, and returns the next value it
+// yields. It returns nil once the fiber has finished.
+//
+// Since Go callbacks are implemented with (yield), a generator cannot call
+// any Go functions itself.
+func (f *Fiber) Resume(ctx context.Context) (*Value, error) {
+	result := make(chan Result)
+	f.vm.requests <- ResumeRequest{
+		Params: Params{
+			Context: ctx,
+			Result:  result,
+		},
+		Fiber: f,
+	}
+
+	select {
+	case result := <-result:
+		return result.Out, result.Error
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (v *VM) resumeFiber(params Params, fiber *Fiber) {
+	var result Result
+	if C.janet_fiber_status(fiber.fiber) != C.JANET_STATUS_DEAD {
+		var out C.Janet
+		signal := C.janet_continue(
+			fiber.fiber,
+			C.janet_wrap_nil(),
+			&out,
+		)
+
+		switch signal {
+		case C.JANET_SIGNAL_YIELD:
+			result.Out = v.value(out)
+		case C.JANET_SIGNAL_OK:
+			// The return value of a generator is ignored, just
+			// like with (each)
+		case C.JANET_SIGNAL_ERROR:
+			var errStr string
+			v.unmarshal(out, &errStr)
+			result.Error = fmt.Errorf(
+				"error while running Janet fiber: %s",
+				errStr,
+			)
+		default:
+			result.Error = fmt.Errorf("unrecognized signal: %d", signal)
+		}
+	}
+
+	// Never block the VM on a caller that has gone away
+	go func() {
+		select {
+		case params.Result <- result:
+		case <-params.Context.Done():
+			if result.Out != nil {
+				result.Out.Free()
+			}
+		}
+	}()
+}
@@ -356,6 +356,12 @@ func isParamType(type_ reflect.Type) bool {
 		return true
 	}
 
+	// Named params can also be functions and the like, which are
+	// unmarshaled but never marshaled
+	if isSpecial(type_) {
+		return true
+	}
+
 	return isValidType(type_)
 }
 
@@ -78,6 +78,8 @@ func (v *VM) poll(ctx context.Context, ready chan bool) {
 			case FiberRequest:
 				params := req.Params
 				v.continueFiber(params, req.Fiber, req.In)
+			case ResumeRequest:
+				v.resumeFiber(req.Params, req.Fiber)
 			case UnlockRequest:
 				req.Value.unroot()
 			case FunctionRequest:
@@ -32,6 +32,10 @@ func isSpecial(type_ reflect.Type) bool {
 		return true
 	}
 
+	if _, ok := reflect.New(type_).Elem().Interface().(*Fiber); ok {
+		return true
+	}
+
 	if _, ok := reflect.New(type_).Elem().Interface().(*Value); ok {
 		return true
 	}
@@ -331,6 +335,19 @@ func (v *VM) unmarshal(source C.Janet, dest interface{}) error {
 			return nil
 		}
 
+		if _, ok := reflect.New(type_).Elem().Interface().(*Fiber); ok {
+			if err := assertType(source, C.JANET_FIBER); err != nil {
+				return err
+			}
+
+			fiber := &Fiber{
+				Value: v.value(source),
+				fiber: C.janet_unwrap_fiber(source),
+			}
+			value.Set(reflect.ValueOf(fiber))
+			return nil
+		}
+
 		if _, ok := reflect.New(type_).Elem().Interface().(*Value); ok {
 			value.Set(reflect.ValueOf(v.value(source)))
 			return nil
@@ -344,7 +361,7 @@ func (v *VM) unmarshal(source C.Janet, dest interface{}) error {
 			}
 			value.Set(ptr)
 		} else {
-			value.Set(reflect.ValueOf(nil))
+			value.Set(reflect.Zero(type_))
 		}
 
 		//return fmt.Errorf("unimplemented pointer type: %s (%s)", type_.String(), type_.Kind().String())
@@ -396,8 +413,11 @@ func (v *VM) unmarshal(source C.Janet, dest interface{}) error {
 			field := type_.Field(i)
 			fieldValue := value.Field(i)
 
-			key_ := wrapKeyword(getFieldName(field))
-			value_ := C.janet_struct_get(struct_, key_)
+			// Creating a keyword allocates, which is not safe
+			// outside of the VM's thread
+			key_ := C.CString(getFieldName(field))
+			value_ := C.keyword_struct_get(struct_, key_)
+			C.free(unsafe.Pointer(key_))
 			err := v.unmarshal(value_, fieldValue.Addr().Interface())
 			if err != nil {
 				return fmt.Errorf("failed to unmarshal struct field %s: %s", field.Name, err.Error())
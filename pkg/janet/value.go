@@ -12,6 +12,7 @@ import "C"
 import (
 	"context"
 	"fmt"
+	"math"
 
 	"github.com/sasha-s/go-deadlock"
 )
@@ -92,6 +93,34 @@ type FunctionRequest struct {
 	Function *Function
 }
 
+// Arity returns the minimum and maximum number of arguments the function
+// accepts. If the function is variadic, `max` is -1.
+func (f *Function) Arity() (min, max int) {
+	def := f.function.def
+	min = int(def.min_arity)
+
+	// Functions that end with a bare & have no maximum
+	if def.flags&C.JANET_FUNCDEF_FLAG_VARARG != 0 || def.max_arity == math.MaxInt32 {
+		return min, -1
+	}
+
+	return min, int(def.max_arity)
+}
+
+// request sends a FunctionRequest to the VM unless the function was freed.
+// Holding the lock until the VM receives the request ensures that the
+// function cannot be unrooted before it is called.
+func (f *Function) request(req FunctionRequest) error {
+	f.RLock()
+	defer f.RUnlock()
+	if f.wasFreed {
+		return ERROR_FREED
+	}
+
+	f.vm.requests <- req
+	return nil
+}
+
 func (f *Function) CallContext(
 	ctx context.Context,
 	user interface{},
@@ -107,7 +136,9 @@ func (f *Function) CallContext(
 			Result:  result,
 		},
 	}
-	f.vm.requests <- req
+	if err := f.request(req); err != nil {
+		return err
+	}
 
 	return req.Wait()
 }
@@ -115,3 +146,33 @@ func (f *Function) CallContext(
 func (f *Function) Call(ctx context.Context, params ...interface{}) error {
 	return f.CallContext(ctx, nil, params...)
 }
+
+// CallResult calls the function and returns the value it produced, which
+// the caller must free.
+func (f *Function) CallResult(
+	ctx context.Context,
+	user interface{},
+	params ...interface{},
+) (*Value, error) {
+	// Buffered so that the VM never blocks if we stop waiting
+	result := make(chan Result, 1)
+	err := f.request(FunctionRequest{
+		Args:     params,
+		Function: f,
+		Params: Params{
+			Context: ctx,
+			User:    user,
+			Result:  result,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case result := <-result:
+		return result.Out, result.Error
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
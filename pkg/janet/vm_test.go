@@ -74,6 +74,80 @@ func TestVM(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("function with a result", func(t *testing.T) {
+		var fun *Function
+		err = vm.Callback("test-result", "", func(f *Function) {
+			fun = f
+		})
+		require.NoError(t, err)
+
+		err = vm.Execute(ctx, `(test-result (fn [a b] (+ a b)))`)
+		require.NoError(t, err)
+		require.NotNil(t, fun)
+
+		value, err := fun.CallResult(ctx, nil, 1, 2)
+		require.NoError(t, err)
+		defer value.Free()
+
+		var sum int
+		require.NoError(t, value.Unmarshal(&sum))
+		require.Equal(t, 3, sum)
+	})
+
+	t.Run("callback with a generator", func(t *testing.T) {
+		var fiber *Fiber
+		err = vm.Callback("test-generator", "", func(f *Fiber) {
+			fiber = f
+		})
+		require.NoError(t, err)
+
+		err = vm.Execute(ctx, `(test-generator (generate [i :range [0 3]] i))`)
+		require.NoError(t, err)
+		require.NotNil(t, fiber)
+
+		var values []int
+		for {
+			value, err := fiber.Resume(ctx)
+			require.NoError(t, err)
+			if value == nil {
+				break
+			}
+
+			var i int
+			require.NoError(t, value.Unmarshal(&i))
+			value.Free()
+			values = append(values, i)
+		}
+		require.Equal(t, []int{0, 1, 2}, values)
+
+		// Resuming a finished generator is not an error
+		value, err := fiber.Resume(ctx)
+		require.NoError(t, err)
+		require.Nil(t, value)
+	})
+
+	t.Run("function arity", func(t *testing.T) {
+		var funcs []*Function
+		err = vm.Callback("test-arity", "", func(f *Function) {
+			funcs = append(funcs, f)
+		})
+		require.NoError(t, err)
+
+		err = vm.Execute(ctx, `
+(test-arity (fn []))
+(test-arity (fn [a &opt b]))
+(test-arity (fn [a &]))
+(test-arity (fn [& args]))
+`)
+		require.NoError(t, err)
+		require.Equal(t, 4, len(funcs))
+
+		for i, expected := range [][2]int{{0, 0}, {1, 2}, {1, -1}, {0, -1}} {
+			min, max := funcs[i].Arity()
+			require.Equal(t, expected, [2]int{min, max})
+		}
+	})
+
 	t.Run("callback with context", func(t *testing.T) {
 		state := 0
 		err = vm.Callback("test-context", "", func(context interface{}) {
@@ -182,6 +256,28 @@ func TestVM(t *testing.T) {
 		require.Equal(t, 1, first)
 	})
 
+	t.Run("callback with a struct value", func(t *testing.T) {
+		type Struct struct {
+			Name     string
+			Optional *string
+		}
+
+		var result Struct
+		err = vm.Callback("test-struct", "", func(value *Value) error {
+			defer value.Free()
+			return value.Unmarshal(&result)
+		})
+		require.NoError(t, err)
+
+		err = vm.Execute(ctx, `(test-struct {:name "one"})`)
+		require.NoError(t, err)
+		require.Equal(t, Struct{Name: "one"}, result)
+
+		err = vm.Execute(ctx, `(test-struct {:name "one" :optional "two"})`)
+		require.NoError(t, err)
+		require.Equal(t, "two", *result.Optional)
+	})
+
 	t.Run("callback with a tuple", func(t *testing.T) {
 		type Tuple struct {
 			_      struct{} `janet:"tuple"`
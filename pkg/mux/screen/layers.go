@@ -86,6 +86,13 @@ func (l *Layers) NumLayers() int {
 	return len(l.layers)
 }
 
+// Size returns the size of the screen the layers are rendered on.
+func (l *Layers) Size() Size {
+	l.RLock()
+	defer l.RUnlock()
+	return l.size
+}
+
 type LayerOption func(*Layer)
 
 func WithInteractive(layer *Layer) {
@@ -155,6 +162,21 @@ func (l *Layers) NewLayer(ctx context.Context, screen Screen, pos Position, opti
 	return layer
 }
 
+// Interactive returns the topmost interactive layer, which receives the
+// messages sent to the layers, or nil if there is none.
+func (l *Layers) Interactive() Screen {
+	l.RLock()
+	defer l.RUnlock()
+
+	for i := len(l.layers) - 1; i >= 0; i-- {
+		if layer := l.layers[i]; layer.isInteractive {
+			return layer.Screen
+		}
+	}
+
+	return nil
+}
+
 func (l *Layers) Send(msg mux.Msg) {
 	l.RLock()
 	layers := l.layers
@@ -0,0 +1,53 @@
+package prompt
+
+import (
+	"context"
+
+	"github.com/cfoust/cy/pkg/geom/tty"
+	"github.com/cfoust/cy/pkg/taro"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Confirm asks the user a yes or no question. It sends true if they press
+// `y` and false if they press `n` or quit.
+type Confirm struct {
+	base
+}
+
+var _ taro.Model = (*Confirm)(nil)
+
+func (c *Confirm) Init() taro.Cmd {
+	return nil
+}
+
+func (c *Confirm) Update(msg tea.Msg) (taro.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		c.resize(msg)
+	case taro.KeyMsg:
+		if !isPress(msg) {
+			return c, nil
+		}
+
+		switch msg.String() {
+		case "y", "Y":
+			return c, c.respond(true)
+		case "n", "N", "esc", "ctrl+c":
+			return c, c.respond(false)
+		}
+	}
+
+	return c, nil
+}
+
+func (c *Confirm) View(state *tty.State) {
+	c.draw(state, c.renderPrompt("y/n"))
+}
+
+func NewConfirm(ctx context.Context, settings ...Setting) *taro.Program {
+	c := &Confirm{
+		base: newBase(ctx, settings),
+	}
+	return taro.New(c.Ctx(), c)
+}
@@ -0,0 +1,48 @@
+package prompt
+
+import (
+	"context"
+
+	"github.com/cfoust/cy/pkg/geom/tty"
+	"github.com/cfoust/cy/pkg/taro"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Key waits for the user to press a single key and sends its name (such as
+// "a" or "ctrl+a"), which is in the same format used for key bindings.
+type Key struct {
+	base
+}
+
+var _ taro.Model = (*Key)(nil)
+
+func (k *Key) Init() taro.Cmd {
+	return nil
+}
+
+func (k *Key) Update(msg tea.Msg) (taro.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		k.resize(msg)
+	case taro.KeyMsg:
+		if !isPress(msg) {
+			return k, nil
+		}
+
+		return k, k.respond(msg.String())
+	}
+
+	return k, nil
+}
+
+func (k *Key) View(state *tty.State) {
+	k.draw(state, k.renderPrompt("press a key"))
+}
+
+func NewKey(ctx context.Context, settings ...Setting) *taro.Program {
+	k := &Key{
+		base: newBase(ctx, settings),
+	}
+	return taro.New(k.Ctx(), k)
+}
@@ -0,0 +1,187 @@
+package prompt
+
+import (
+	"context"
+
+	"github.com/cfoust/cy/pkg/geom"
+	"github.com/cfoust/cy/pkg/geom/tty"
+	"github.com/cfoust/cy/pkg/taro"
+	"github.com/cfoust/cy/pkg/util"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// The minimum width of a prompt window, in cells.
+const MIN_WIDTH = 30
+
+// base contains the state shared by every kind of prompt. Prompts are small
+// windows drawn next to a location on the screen (typically the cursor),
+// just like an inline Fuzzy.
+type base struct {
+	util.Lifetime
+	render *taro.Renderer
+
+	result chan<- interface{}
+	prompt string
+
+	size     geom.Vec2
+	location geom.Vec2
+
+	// Only used by Text
+	value       string
+	placeholder string
+	validate    func(value string) error
+}
+
+type Setting func(*base)
+
+// Sends the user's response to `result`. Prompts send nil if the user quits
+// without responding.
+func WithResult(result chan<- interface{}) Setting {
+	return func(b *base) {
+		b.result = result
+	}
+}
+
+// The text shown to the user describing what they should do.
+func WithPrompt(prompt string) Setting {
+	return func(b *base) {
+		b.prompt = prompt
+	}
+}
+
+// Displays the prompt next to this location on the screen.
+func WithInline(location geom.Vec2) Setting {
+	return func(b *base) {
+		b.location = location
+	}
+}
+
+func newBase(ctx context.Context, settings []Setting) base {
+	b := base{
+		Lifetime: util.NewLifetime(ctx),
+		render:   taro.NewRenderer(),
+	}
+
+	for _, setting := range settings {
+		setting(&b)
+	}
+
+	return b
+}
+
+// respond sends `value` to whoever is waiting for the prompt's result and
+// closes the prompt.
+func (b *base) respond(value interface{}) tea.Cmd {
+	if b.result != nil {
+		select {
+		case b.result <- value:
+		case <-b.Ctx().Done():
+		}
+	}
+
+	return tea.Batch(
+		func() tea.Msg {
+			b.Cancel()
+			return nil
+		},
+		tea.Quit,
+	)
+}
+
+func (b *base) resize(msg tea.WindowSizeMsg) {
+	b.size = geom.Size{
+		R: msg.Height,
+		C: msg.Width,
+	}
+	b.location = geom.Vec2{
+		R: geom.Clamp(b.location.R, 0, b.size.R-1),
+		C: geom.Clamp(b.location.C, 0, b.size.C-1),
+	}
+}
+
+// isUp reports whether the prompt should be drawn above its location
+// rather than below it, which is the case when it is in the bottom half of
+// the screen.
+func (b *base) isUp() bool {
+	return b.location.R > (b.size.R / 2)
+}
+
+// width returns the width of the prompt window.
+func (b *base) width() int {
+	width := geom.Max(MIN_WIDTH, lipgloss.Width(b.prompt)+12)
+	if b.size.C > 0 {
+		width = geom.Min(width, b.size.C)
+	}
+	return width
+}
+
+// draw renders `blocks` at the prompt's location. The blocks are stacked
+// from top to bottom, or from bottom to top when the prompt is drawn above
+// its location, so that the first block is always closest to it.
+func (b *base) draw(state *tty.State, blocks ...string) {
+	// Text draws its own cursor
+	state.CursorVisible = false
+
+	var visible []string
+	for _, block := range blocks {
+		if len(block) == 0 {
+			continue
+		}
+
+		if b.isUp() {
+			visible = append([]string{block}, visible...)
+		} else {
+			visible = append(visible, block)
+		}
+	}
+
+	output := lipgloss.JoinVertical(lipgloss.Left, visible...)
+
+	row := b.location.R
+	if b.isUp() {
+		row -= lipgloss.Height(output)
+	}
+
+	b.render.RenderAt(
+		state.Image,
+		geom.Max(row, 0),
+		geom.Clamp(b.location.C, 0, b.size.C-lipgloss.Width(output)),
+		output,
+	)
+}
+
+func (b *base) commonStyle() lipgloss.Style {
+	return b.render.NewStyle().
+		Background(lipgloss.Color("#20111B")).
+		Foreground(lipgloss.Color("#D5CCBA")).
+		Width(b.width())
+}
+
+// renderPrompt renders the prompt with `hint` aligned to the right.
+func (b *base) renderPrompt(hint string) string {
+	width := b.width()
+	style := b.render.NewStyle().
+		Background(lipgloss.Color("#EAA549")).
+		Foreground(lipgloss.Color("#20111B")).
+		Width(width)
+
+	return style.Render(
+		lipgloss.JoinHorizontal(
+			lipgloss.Left,
+			b.prompt,
+			lipgloss.PlaceHorizontal(
+				width-lipgloss.Width(b.prompt),
+				lipgloss.Right,
+				hint,
+			),
+		),
+	)
+}
+
+// isPress reports whether `msg` is a key press (or repeat) as opposed to a
+// key release, which prompts ignore.
+func isPress(msg taro.KeyMsg) bool {
+	return msg.Event != taro.KeyEventRelease
+}
@@ -0,0 +1,87 @@
+package prompt
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cfoust/cy/pkg/taro"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+)
+
+// sendKeys sends each key to `model` and returns the last command it
+// produced.
+func sendKeys(model taro.Model, keys ...string) (cmd tea.Cmd) {
+	for _, msg := range taro.KeysToMsg(keys...) {
+		_, cmd = model.Update(msg)
+	}
+	return
+}
+
+func TestText(t *testing.T) {
+	result := make(chan interface{}, 1)
+	text := newText(
+		context.Background(),
+		WithResult(result),
+		WithDefault("foo"),
+		WithValidate(func(value string) error {
+			if value == "foobar" {
+				return fmt.Errorf("no")
+			}
+			return nil
+		}),
+	)
+
+	// Rejected values show an error and keep the prompt open
+	cmd := sendKeys(text, "b", "a", "r", "enter")
+	require.NotNil(t, cmd)
+	text.Update(cmd())
+	require.Equal(t, "no", text.err)
+	require.Len(t, result, 0)
+
+	sendKeys(text, "backspace")
+	require.Equal(t, "", text.err)
+
+	cmd = sendKeys(text, "enter")
+	text.Update(cmd())
+	require.Equal(t, "fooba", <-result)
+}
+
+func TestTextQuit(t *testing.T) {
+	result := make(chan interface{}, 1)
+	text := newText(context.Background(), WithResult(result))
+	sendKeys(text, "a", "esc")
+	require.Nil(t, <-result)
+}
+
+func TestConfirm(t *testing.T) {
+	for key, expected := range map[string]bool{
+		"y":   true,
+		"n":   false,
+		"esc": false,
+	} {
+		result := make(chan interface{}, 1)
+		confirm := &Confirm{
+			base: newBase(context.Background(), []Setting{WithResult(result)}),
+		}
+
+		// Other keys are ignored
+		sendKeys(confirm, "a")
+		require.Len(t, result, 0)
+
+		sendKeys(confirm, key)
+		require.Equal(t, expected, <-result)
+	}
+}
+
+func TestKey(t *testing.T) {
+	result := make(chan interface{}, 1)
+	key := &Key{
+		base: newBase(context.Background(), []Setting{WithResult(result)}),
+	}
+
+	sendKeys(key, "ctrl+a")
+	require.Equal(t, "ctrl+a", <-result)
+}
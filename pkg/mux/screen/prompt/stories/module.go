@@ -0,0 +1,71 @@
+package stories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cfoust/cy/pkg/geom"
+	"github.com/cfoust/cy/pkg/mux"
+	"github.com/cfoust/cy/pkg/mux/screen/prompt"
+	"github.com/cfoust/cy/pkg/stories"
+)
+
+var Text stories.InitFunc = func(ctx context.Context) mux.Screen {
+	return prompt.NewText(
+		ctx,
+		prompt.WithPrompt("rename pane"),
+		prompt.WithDefault("editor"),
+	)
+}
+
+var Placeholder stories.InitFunc = func(ctx context.Context) mux.Screen {
+	return prompt.NewText(
+		ctx,
+		prompt.WithPrompt("new project"),
+		prompt.WithPlaceholder("project name"),
+	)
+}
+
+var Invalid stories.InitFunc = func(ctx context.Context) mux.Screen {
+	t := prompt.NewText(
+		ctx,
+		prompt.WithPrompt("rename pane"),
+		prompt.WithInline(geom.Vec2{R: 20, C: 10}),
+		prompt.WithValidate(func(value string) error {
+			if strings.Contains(value, " ") {
+				return fmt.Errorf("names cannot contain spaces")
+			}
+			return nil
+		}),
+	)
+
+	stories.Send(t, "my", "space", "pane", "enter")
+	return t
+}
+
+var Confirm stories.InitFunc = func(ctx context.Context) mux.Screen {
+	return prompt.NewConfirm(
+		ctx,
+		prompt.WithPrompt("kill this pane?"),
+		prompt.WithInline(geom.Vec2{R: 5, C: 5}),
+	)
+}
+
+var Key stories.InitFunc = func(ctx context.Context) mux.Screen {
+	return prompt.NewKey(
+		ctx,
+		prompt.WithPrompt("register"),
+	)
+}
+
+func init() {
+	config := stories.Config{
+		Size: geom.DEFAULT_SIZE,
+	}
+	stories.Register("input/text", Text, config)
+	stories.Register("input/text/placeholder", Placeholder, config)
+	stories.Register("input/text/invalid", Invalid, config)
+	stories.Register("input/confirm", Confirm, config)
+	stories.Register("input/key", Key, config)
+}
@@ -0,0 +1,165 @@
+package prompt
+
+import (
+	"context"
+
+	"github.com/cfoust/cy/pkg/geom/tty"
+	"github.com/cfoust/cy/pkg/taro"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Text asks the user to type a single line of text.
+type Text struct {
+	base
+	textInput textinput.Model
+
+	// whether we are waiting for `validate` to finish
+	isValidating bool
+	// the reason the last value the user submitted was rejected
+	err string
+}
+
+var _ taro.Model = (*Text)(nil)
+
+// The initial value of the text input.
+func WithDefault(value string) Setting {
+	return func(b *base) {
+		b.value = value
+	}
+}
+
+// Text shown in the text input when it is empty.
+func WithPlaceholder(placeholder string) Setting {
+	return func(b *base) {
+		b.placeholder = placeholder
+	}
+}
+
+// Called when the user submits a value. If it returns an error, the value
+// is rejected and the error is shown to the user.
+func WithValidate(validate func(value string) error) Setting {
+	return func(b *base) {
+		b.validate = validate
+	}
+}
+
+type validated struct {
+	value string
+	err   error
+}
+
+func (t *Text) Init() taro.Cmd {
+	return textinput.Blink
+}
+
+func (t *Text) submit() (taro.Model, tea.Cmd) {
+	if t.isValidating {
+		return t, nil
+	}
+
+	value := t.textInput.Value()
+	if t.validate == nil {
+		return t, t.respond(value)
+	}
+
+	// Validation may take a while (it may even call into Janet), so we
+	// don't want to block the UI
+	t.isValidating = true
+	return t, func() tea.Msg {
+		return validated{
+			value: value,
+			err:   t.validate(value),
+		}
+	}
+}
+
+func (t *Text) Update(msg tea.Msg) (taro.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		t.resize(msg)
+		t.textInput.Width = t.width() - 1
+		return t, nil
+	case validated:
+		t.isValidating = false
+		if msg.err != nil {
+			t.err = msg.err.Error()
+			return t, nil
+		}
+
+		return t, t.respond(msg.value)
+	case taro.KeyMsg:
+		if !isPress(msg) {
+			return t, nil
+		}
+
+		switch msg.Type {
+		case taro.KeyEsc, taro.KeyCtrlC:
+			return t, t.respond(nil)
+		case taro.KeyEnter:
+			return t.submit()
+		}
+
+		// Editing the value clears the last error
+		t.err = ""
+
+		var cmd tea.Cmd
+		t.textInput, cmd = t.textInput.Update(msg.ToTea())
+		return t, cmd
+	}
+
+	var cmd tea.Cmd
+	t.textInput, cmd = t.textInput.Update(msg)
+	return t, cmd
+}
+
+func (t *Text) View(state *tty.State) {
+	common := t.commonStyle()
+
+	t.textInput.Cursor.Style = t.render.NewStyle().
+		Background(lipgloss.Color("#E8E3DF"))
+	t.textInput.PlaceholderStyle = t.render.NewStyle().
+		Foreground(lipgloss.Color("#968C83"))
+
+	hint := ""
+	if t.isValidating {
+		hint = "…"
+	}
+
+	var err string
+	if len(t.err) > 0 {
+		err = common.Copy().
+			Foreground(lipgloss.Color("#E86A5D")).
+			Render(t.err)
+	}
+
+	t.draw(
+		state,
+		common.Render(t.textInput.View()),
+		t.renderPrompt(hint),
+		err,
+	)
+}
+
+func newText(ctx context.Context, settings ...Setting) *Text {
+	t := &Text{
+		base: newBase(ctx, settings),
+	}
+
+	ti := textinput.New()
+	ti.Focus()
+	ti.Prompt = ""
+	ti.Placeholder = t.placeholder
+	ti.Width = t.width() - 1
+	ti.SetValue(t.value)
+	ti.CursorEnd()
+	t.textInput = ti
+	return t
+}
+
+func NewText(ctx context.Context, settings ...Setting) *taro.Program {
+	t := newText(ctx, settings...)
+	return taro.New(t.Ctx(), t)
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/cfoust/cy/pkg/sessions/search"
+	"github.com/cfoust/cy/pkg/taro"
 )
 
 type SearchResultEvent struct {
@@ -37,6 +38,9 @@ type CopyEvent struct {
 	Text string
 }
 
+// MouseEvent is a mouse event that did not match any binding.
+type MouseEvent taro.MouseMsg
+
 type Mode uint8
 
 const (
@@ -64,6 +64,9 @@ type Replay struct {
 
 	// Whether the user has started selecting.
 	isSelecting bool
+	// Whether the user is holding down the left mouse button after
+	// clicking inside of the viewport
+	isMouseDown bool
 	// The location in terminal space where the select began
 	selectStart geom.Vec2
 
@@ -177,6 +180,9 @@ func New(
 ) *taro.Program {
 	engine := bind.NewEngine[bind.Action]()
 	engine.SetScopes(replayBinds)
+	// Nothing typed in replay mode goes to a pane, so counts are
+	// always available
+	engine.SetCounts(true)
 	go engine.Poll(ctx)
 	r := newReplay(events, engine)
 	for _, option := range options {
@@ -190,8 +196,11 @@ func New(
 			case <-ctx.Done():
 				return
 			case event := <-engine.Recv():
-				if bindEvent, ok := event.(bind.BindEvent); ok {
-					program.Publish(bindEvent)
+				switch event := event.(type) {
+				case bind.BindEvent:
+					program.Publish(event)
+				case taro.MouseMsg:
+					program.Send(MouseEvent(event))
 				}
 			}
 		}
@@ -0,0 +1,69 @@
+package replay
+
+import (
+	"github.com/cfoust/cy/pkg/taro"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Handle mouse events that were not bound to anything. The scroll wheel
+// scrolls the viewport, clicking moves the cursor, and dragging selects text,
+// which is copied when the user releases the button.
+func (r *Replay) handleMouse(msg taro.MouseMsg) (taro.Model, tea.Cmd) {
+	switch msg.Button {
+	case taro.MouseWheelUp:
+		r.setScrollY(r.offset.R - 1)
+		return r, nil
+	case taro.MouseWheelDown:
+		r.setScrollY(r.offset.R + 1)
+		// TODO(cfoust): 11/22/23 what should happen to cursor?
+		//case taro.MouseWheelLeft:
+		//r.setScrollX(r.offset.C - 1)
+		//case taro.MouseWheelRight:
+		//r.setScrollX(r.offset.C + 1)
+		return r, nil
+	}
+
+	// Releases are reported without a button
+	if msg.Type == taro.MousePress && !msg.Down {
+		if !r.isMouseDown {
+			return r, nil
+		}
+
+		r.isMouseDown = false
+		return r.handleCopy()
+	}
+
+	if msg.Button != taro.MouseLeft || !msg.Down {
+		return r, nil
+	}
+
+	switch msg.Type {
+	case taro.MousePress:
+		if !r.isInViewport(msg.Vec2) {
+			return r, nil
+		}
+
+		r.isPlaying = false
+		r.mode = ModeCopy
+		r.isSelecting = false
+		r.isMouseDown = true
+		r.moveCursor(r.clampToTerminal(r.viewportToTerm(msg.Vec2)))
+		r.desiredCol = r.cursor.C
+	case taro.MouseMotion:
+		if !r.isMouseDown {
+			return r, nil
+		}
+
+		if !r.isSelecting {
+			r.isSelecting = true
+			r.selectStart = r.viewportToTerm(r.cursor)
+		}
+
+		// Dragging outside of the viewport scrolls it
+		r.moveCursor(r.clampToTerminal(r.viewportToTerm(msg.Vec2)))
+		r.desiredCol = r.cursor.C
+	}
+
+	return r, nil
+}
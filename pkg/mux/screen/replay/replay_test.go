@@ -410,3 +410,49 @@ func TestJump(t *testing.T) {
 	i(arg(ActionJumpToBackward, "e"))
 	require.Equal(t, geom.Vec2{C: 8}, r.cursor)
 }
+
+func TestMouse(t *testing.T) {
+	s := sessions.NewSimulator()
+	s.Add(
+		geom.Size{R: 5, C: 10},
+		emu.LineFeedMode,
+		"foo\n",
+		"bar baz\n",
+	)
+
+	r, i := createTest(s.Events())
+	i(geom.Size{R: 6, C: 10})
+
+	// Clicking moves the cursor
+	i(MouseEvent{
+		Vec2:   geom.Vec2{R: 1, C: 2},
+		Type:   taro.MousePress,
+		Button: taro.MouseLeft,
+		Down:   true,
+	})
+	require.True(t, r.isCopyMode())
+	require.False(t, r.isSelecting)
+	require.Equal(t, geom.Vec2{R: 1, C: 2}, r.viewportToTerm(r.cursor))
+
+	// Dragging selects
+	i(MouseEvent{
+		Vec2:   geom.Vec2{R: 1, C: 5},
+		Type:   taro.MouseMotion,
+		Button: taro.MouseLeft,
+		Down:   true,
+	})
+	require.True(t, r.isSelecting)
+	require.Equal(t, geom.Vec2{R: 1, C: 2}, r.selectStart)
+	require.Equal(t, geom.Vec2{R: 1, C: 5}, r.viewportToTerm(r.cursor))
+	require.Equal(t, "r ba", r.readString(
+		r.selectStart,
+		r.viewportToTerm(r.cursor),
+	))
+
+	// Releasing the button copies the selection
+	i(MouseEvent{
+		Type: taro.MousePress,
+	})
+	require.False(t, r.isSelecting)
+	require.False(t, r.isMouseDown)
+}
@@ -85,21 +85,18 @@ func (r *Replay) Update(msg tea.Msg) (taro.Model, tea.Cmd) {
 			r.binds.InputMessage(msg)
 			return nil
 		}
+	case taro.MouseMsg:
+		// Mouse events can be bound too; the ones that aren't come
+		// back to us as MouseEvents
+		return r, func() tea.Msg {
+			r.binds.InputMessage(msg)
+			return nil
+		}
+	case MouseEvent:
+		return r.handleMouse(taro.MouseMsg(msg))
 	}
 
 	switch msg := msg.(type) {
-	case taro.MouseMsg:
-		switch msg.Button {
-		case taro.MouseWheelUp:
-			r.setScrollY(r.offset.R - 1)
-		case taro.MouseWheelDown:
-			r.setScrollY(r.offset.R + 1)
-			// TODO(cfoust): 11/22/23 what should happen to cursor?
-			//case taro.MouseWheelLeft:
-			//r.setScrollX(r.offset.C - 1)
-			//case taro.MouseWheelRight:
-			//r.setScrollX(r.offset.C + 1)
-		}
 	case ActionEvent:
 		r.isPlaying = false
 		switch msg.Type {
@@ -36,6 +36,13 @@ func (r *Replayable) Screen() mux.Screen {
 	return r.screen
 }
 
+// IsReplaying reports whether the user is currently in replay mode.
+func (r *Replayable) IsReplaying() bool {
+	r.RLock()
+	defer r.RUnlock()
+	return r.replay != nil
+}
+
 func (r *Replayable) EnterReplay() {
 	r.Lock()
 	defer r.Unlock()
@@ -50,6 +57,7 @@ func (r *Replayable) EnterReplay() {
 		r.recorder.Events(),
 		r.binds,
 	)
+	replay.Resize(r.Size())
 
 	r.NewLayer(
 		replay.Ctx(),
@@ -0,0 +1,304 @@
+package status
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cfoust/cy/pkg/emu"
+	"github.com/cfoust/cy/pkg/geom"
+	"github.com/cfoust/cy/pkg/geom/image"
+	"github.com/cfoust/cy/pkg/taro"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Style describes how a Span should be drawn. Colors are in any format
+// lipgloss understands; an empty color means the terminal's default.
+type Style struct {
+	FG, BG    string
+	Bold      bool
+	Dim       bool
+	Italic    bool
+	Underline bool
+	Blink     bool
+	Reverse   bool
+}
+
+// Span is a run of text that is drawn in a single style.
+type Span struct {
+	Style
+	Text string
+}
+
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignCentre
+	AlignRight
+)
+
+// Line is a parsed status line. Every section is drawn with the given
+// alignment; the left section takes priority over the others when they
+// overlap.
+type Line struct {
+	// The background color of the cells not covered by any text
+	Fill     string
+	Sections [3][]Span
+}
+
+var colorNames = map[string]int{
+	"black":         0,
+	"red":           1,
+	"green":         2,
+	"yellow":        3,
+	"blue":          4,
+	"magenta":       5,
+	"cyan":          6,
+	"white":         7,
+	"brightblack":   8,
+	"brightred":     9,
+	"brightgreen":   10,
+	"brightyellow":  11,
+	"brightblue":    12,
+	"brightmagenta": 13,
+	"brightcyan":    14,
+	"brightwhite":   15,
+}
+
+// parseColor translates a markup color into a lipgloss color.
+func parseColor(color string) (string, error) {
+	if color == "default" {
+		return "", nil
+	}
+
+	if index, ok := colorNames[color]; ok {
+		return strconv.Itoa(index), nil
+	}
+
+	if strings.HasPrefix(color, "#") {
+		_, err := strconv.ParseUint(color[1:], 16, 32)
+		if len(color) != 7 || err != nil {
+			return "", fmt.Errorf("invalid color: %s", color)
+		}
+		return color, nil
+	}
+
+	number := color
+	for _, prefix := range []string{"colour", "color"} {
+		number = strings.TrimPrefix(number, prefix)
+	}
+
+	index, err := strconv.Atoi(number)
+	if err != nil || index < 0 || index > 255 {
+		return "", fmt.Errorf("invalid color: %s", color)
+	}
+
+	return strconv.Itoa(index), nil
+}
+
+// apply changes the style and alignment according to a single attribute
+// found in a style tag.
+func (l *Line) apply(style *Style, align *Align, attribute string) error {
+	if key, value, ok := strings.Cut(attribute, "="); ok {
+		switch key {
+		case "fg", "bg", "fill":
+			color, err := parseColor(value)
+			if err != nil {
+				return err
+			}
+
+			switch key {
+			case "fg":
+				style.FG = color
+			case "bg":
+				style.BG = color
+			default:
+				l.Fill = color
+			}
+		case "align":
+			switch value {
+			case "left":
+				*align = AlignLeft
+			case "centre", "center":
+				*align = AlignCentre
+			case "right":
+				*align = AlignRight
+			default:
+				return fmt.Errorf("invalid alignment: %s", value)
+			}
+		default:
+			return fmt.Errorf("unknown attribute: %s", key)
+		}
+		return nil
+	}
+
+	switch attribute {
+	case "default":
+		*style = Style{}
+		return nil
+	case "none":
+		*style = Style{FG: style.FG, BG: style.BG}
+		return nil
+	}
+
+	name, enabled := attribute, true
+	if trimmed, ok := strings.CutPrefix(attribute, "no"); ok {
+		name, enabled = trimmed, false
+	}
+
+	switch name {
+	case "bold", "bright":
+		style.Bold = enabled
+	case "dim":
+		style.Dim = enabled
+	case "italics", "italic":
+		style.Italic = enabled
+	case "underscore", "underline":
+		style.Underline = enabled
+	case "blink":
+		style.Blink = enabled
+	case "reverse":
+		style.Reverse = enabled
+	default:
+		return fmt.Errorf("unknown attribute: %s", attribute)
+	}
+
+	return nil
+}
+
+// Parse reads a status line written in cy's markup, which is a subset of
+// the one tmux uses for its status line. Style tags like
+// `#[fg=red,bold]` change the style of the text that follows them and
+// `##` produces a literal `#`.
+func Parse(markup string) (line Line, err error) {
+	var (
+		style Style
+		align Align
+		text  strings.Builder
+	)
+
+	flush := func() {
+		if text.Len() == 0 {
+			return
+		}
+
+		line.Sections[align] = append(line.Sections[align], Span{
+			Style: style,
+			Text:  text.String(),
+		})
+		text.Reset()
+	}
+
+	for i := 0; i < len(markup); i++ {
+		if markup[i] != '#' || i+1 == len(markup) {
+			text.WriteByte(markup[i])
+			continue
+		}
+
+		switch markup[i+1] {
+		case '#':
+			text.WriteByte('#')
+			i++
+			continue
+		case '[':
+		default:
+			text.WriteByte('#')
+			continue
+		}
+
+		end := strings.IndexByte(markup[i:], ']')
+		if end == -1 {
+			return Line{}, fmt.Errorf("unterminated style tag at %d", i)
+		}
+
+		flush()
+
+		tag := markup[i+2 : i+end]
+		for _, attribute := range strings.FieldsFunc(
+			tag,
+			func(r rune) bool { return r == ',' || r == ' ' },
+		) {
+			err = line.apply(&style, &align, attribute)
+			if err != nil {
+				return Line{}, err
+			}
+		}
+
+		i += end
+	}
+
+	flush()
+	return line, nil
+}
+
+// Escape makes `text` safe to include in markup by escaping every `#`.
+func Escape(text string) string {
+	return strings.ReplaceAll(text, "#", "##")
+}
+
+func (s Style) render(r *taro.Renderer, text string) string {
+	style := r.NewStyle().
+		Bold(s.Bold).
+		Faint(s.Dim).
+		Italic(s.Italic).
+		Underline(s.Underline).
+		Blink(s.Blink).
+		Reverse(s.Reverse)
+
+	if len(s.FG) > 0 {
+		style = style.Foreground(lipgloss.Color(s.FG))
+	}
+
+	if len(s.BG) > 0 {
+		style = style.Background(lipgloss.Color(s.BG))
+	}
+
+	return style.Render(text)
+}
+
+// Render draws the line into an image `width` cells wide.
+func (l Line) Render(r *taro.Renderer, width int) image.Image {
+	output := image.New(geom.Vec2{R: 1, C: width})
+	if width <= 0 {
+		return output
+	}
+
+	// Sections drawn later take priority, so we draw the left section
+	// last
+	for _, align := range []Align{AlignRight, AlignCentre, AlignLeft} {
+		var rendered string
+		for _, span := range l.Sections[align] {
+			// Newlines would push the rest of the line out of view
+			text := strings.ReplaceAll(span.Text, "\n", " ")
+			rendered += span.render(r, text)
+		}
+
+		if len(rendered) == 0 {
+			continue
+		}
+
+		col := 0
+		switch align {
+		case AlignCentre:
+			col = (width - lipgloss.Width(rendered)) / 2
+		case AlignRight:
+			col = width - lipgloss.Width(rendered)
+		}
+
+		section := r.RenderImage(rendered)
+		image.Copy(geom.Vec2{C: geom.Max(col, 0)}, output, section)
+	}
+
+	if len(l.Fill) > 0 {
+		fill := r.ConvertLipgloss(lipgloss.Color(l.Fill))
+		for col, cell := range output[0] {
+			if cell.BG == emu.DefaultBG {
+				output[0][col].BG = fill
+			}
+		}
+	}
+
+	return output
+}
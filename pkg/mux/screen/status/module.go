@@ -0,0 +1,191 @@
+package status
+
+import (
+	"context"
+
+	"github.com/cfoust/cy/pkg/geom"
+	"github.com/cfoust/cy/pkg/geom/image"
+	"github.com/cfoust/cy/pkg/geom/tty"
+	"github.com/cfoust/cy/pkg/mux"
+	"github.com/cfoust/cy/pkg/taro"
+
+	"github.com/sasha-s/go-deadlock"
+)
+
+type Position int
+
+const (
+	PositionBottom Position = iota
+	PositionTop
+)
+
+// Status draws a single line of text above or below a Screen, shrinking
+// the Screen to make room for it. When the status line is hidden, the
+// Screen gets all of the space.
+type Status struct {
+	deadlock.RWMutex
+	*mux.UpdatePublisher
+	render *taro.Renderer
+
+	screen mux.Screen
+
+	isVisible bool
+	position  Position
+	line      Line
+	// the line rendered at the current width, since rendering is not
+	// cheap and State is called often
+	rendered image.Image
+
+	size geom.Size
+}
+
+var _ mux.Screen = (*Status)(nil)
+
+// getLayout returns the row on which the status line is drawn and the
+// position of the inner Screen.
+func (s *Status) getLayout() (row int, inner geom.Rect) {
+	inner.Size = s.size
+	if !s.isVisible {
+		return -1, inner
+	}
+
+	inner.Size.R = geom.Max(s.size.R-1, 0)
+	if s.position == PositionTop {
+		inner.Position.R = 1
+		return 0, inner
+	}
+
+	return s.size.R - 1, inner
+}
+
+// Inner returns the region of the screen occupied by the inner Screen.
+func (s *Status) Inner() geom.Rect {
+	s.RLock()
+	defer s.RUnlock()
+	_, inner := s.getLayout()
+	return inner
+}
+
+func (s *Status) State() *tty.State {
+	s.RLock()
+	size := s.size
+	rendered := s.rendered
+	row, inner := s.getLayout()
+	s.RUnlock()
+
+	innerState := s.screen.State()
+	if row == -1 {
+		return innerState
+	}
+
+	state := tty.New(size)
+	tty.Copy(inner.Position, state, innerState)
+	image.Copy(geom.Vec2{R: row}, state.Image, rendered)
+	return state
+}
+
+func (s *Status) Send(msg mux.Msg) {
+	s.RLock()
+	_, inner := s.getLayout()
+	s.RUnlock()
+
+	s.screen.Send(taro.TranslateMouseMessage(
+		msg,
+		0,
+		-inner.Position.R,
+	))
+}
+
+// recalculate resizes the inner Screen to fit the current layout.
+func (s *Status) recalculate() error {
+	s.RLock()
+	_, inner := s.getLayout()
+	s.RUnlock()
+
+	err := s.screen.Resize(inner.Size)
+	if err != nil {
+		return err
+	}
+
+	s.Notify()
+	return nil
+}
+
+func (s *Status) Resize(size geom.Size) error {
+	s.Lock()
+	s.size = size
+	s.rendered = s.line.Render(s.render, size.C)
+	s.Unlock()
+	return s.recalculate()
+}
+
+// SetVisible shows or hides the status line.
+func (s *Status) SetVisible(visible bool) error {
+	s.Lock()
+	changed := s.isVisible != visible
+	s.isVisible = visible
+	s.Unlock()
+
+	if !changed {
+		return nil
+	}
+
+	return s.recalculate()
+}
+
+func (s *Status) Visible() bool {
+	s.RLock()
+	defer s.RUnlock()
+	return s.isVisible
+}
+
+// SetPosition changes whether the status line is drawn above or below the
+// Screen.
+func (s *Status) SetPosition(position Position) error {
+	s.Lock()
+	changed := s.position != position
+	s.position = position
+	s.Unlock()
+
+	if !changed {
+		return nil
+	}
+
+	return s.recalculate()
+}
+
+// SetLine replaces the contents of the status line.
+func (s *Status) SetLine(line Line) {
+	s.Lock()
+	s.line = line
+	s.rendered = line.Render(s.render, s.size.C)
+	s.Unlock()
+	s.Notify()
+}
+
+func (s *Status) poll(ctx context.Context) {
+	updates := s.screen.Subscribe(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-updates.Recv():
+			s.Publish(event)
+		}
+	}
+}
+
+func New(ctx context.Context, screen mux.Screen) *Status {
+	status := &Status{
+		UpdatePublisher: mux.NewPublisher(),
+		render:          taro.NewRenderer(),
+		screen:          screen,
+		size:            geom.DEFAULT_SIZE,
+	}
+	status.rendered = status.line.Render(status.render, status.size.C)
+
+	go status.poll(ctx)
+
+	return status
+}
@@ -0,0 +1,85 @@
+package status
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cfoust/cy/pkg/emu"
+	"github.com/cfoust/cy/pkg/geom"
+	"github.com/cfoust/cy/pkg/mux/screen"
+	"github.com/cfoust/cy/pkg/taro"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	line, err := Parse("a#[fg=red,bold]b#[nobold bg=colour100]c##d#e#[default,align=right]f")
+	require.NoError(t, err)
+	require.Equal(t, []Span{
+		{Text: "a"},
+		{Style: Style{FG: "1", Bold: true}, Text: "b"},
+		{Style: Style{FG: "1", BG: "100"}, Text: "c#d#e"},
+	}, line.Sections[AlignLeft])
+	require.Equal(t, []Span{{Text: "f"}}, line.Sections[AlignRight])
+
+	line, err = Parse("#[fill=#ff0000,reverse,none,align=centre]a")
+	require.NoError(t, err)
+	require.Equal(t, "#ff0000", line.Fill)
+	require.Equal(t, []Span{{Text: "a"}}, line.Sections[AlignCentre])
+
+	for _, markup := range []string{
+		"#[fg=red",
+		"#[fg=nope]",
+		"#[fg=256]",
+		"#[bg=#ff00]",
+		"#[align=up]",
+		"#[sparkly]",
+	} {
+		_, err = Parse(markup)
+		require.Error(t, err, markup)
+	}
+
+	require.Equal(t, "a##b", Escape("a#b"))
+}
+
+func TestRender(t *testing.T) {
+	r := taro.NewRenderer()
+	line, err := Parse("#[fill=blue]#[bold]left#[align=centre]c#[align=right,nobold,fg=red]right")
+	require.NoError(t, err)
+
+	image := line.Render(r, 15)
+	require.Equal(t, "left   c  right", image[0].String())
+	require.NotZero(t, image[0][0].Mode&emu.AttrBold)
+	require.Equal(t, emu.Color(emu.Red), image[0][10].FG)
+	require.Equal(t, emu.Color(emu.Blue), image[0][4].BG)
+
+	// The left section takes priority
+	image = line.Render(r, 6)
+	require.Equal(t, "leftht", image[0].String())
+}
+
+func TestStatus(t *testing.T) {
+	ctx := context.Background()
+	layers := screen.NewLayers()
+	status := New(ctx, layers)
+
+	size := geom.Size{R: 10, C: 20}
+	require.NoError(t, status.Resize(size))
+	require.Equal(t, size, layers.Size())
+
+	line, err := Parse("status")
+	require.NoError(t, err)
+	status.SetLine(line)
+
+	require.NoError(t, status.SetVisible(true))
+	require.Equal(t, geom.Size{R: 9, C: 20}, layers.Size())
+	require.Equal(t, geom.Rect{Size: geom.Size{R: 9, C: 20}}, status.Inner())
+	require.Equal(t, "status", status.State().Image[9].String()[:6])
+
+	require.NoError(t, status.SetPosition(PositionTop))
+	require.Equal(t, geom.Vec2{R: 1}, status.Inner().Position)
+	require.Equal(t, "status", status.State().Image[0].String()[:6])
+
+	require.NoError(t, status.SetVisible(false))
+	require.Equal(t, size, layers.Size())
+}
@@ -0,0 +1,58 @@
+package stories
+
+import (
+	"context"
+
+	"github.com/cfoust/cy/pkg/frames"
+	"github.com/cfoust/cy/pkg/geom"
+	"github.com/cfoust/cy/pkg/mux"
+	"github.com/cfoust/cy/pkg/mux/screen/status"
+	"github.com/cfoust/cy/pkg/stories"
+)
+
+func newStatus(
+	ctx context.Context,
+	position status.Position,
+	markup string,
+) mux.Screen {
+	s := status.New(
+		ctx,
+		frames.NewFramer(ctx, frames.Puzzle),
+	)
+	s.SetVisible(true)
+	s.SetPosition(position)
+
+	line, err := status.Parse(markup)
+	if err != nil {
+		panic(err)
+	}
+	s.SetLine(line)
+	return s
+}
+
+const DEFAULT_LINE = "#[fill=black,fg=white]#[fg=black,bg=yellow,bold] REC @q #[default,fg=white] /shells/cy#[align=right]#[fg=red]!1 ##2 #[fg=white]12:34 "
+
+var Bottom stories.InitFunc = func(ctx context.Context) mux.Screen {
+	return newStatus(ctx, status.PositionBottom, DEFAULT_LINE)
+}
+
+var Top stories.InitFunc = func(ctx context.Context) mux.Screen {
+	return newStatus(ctx, status.PositionTop, DEFAULT_LINE)
+}
+
+var Styles stories.InitFunc = func(ctx context.Context) mux.Screen {
+	return newStatus(
+		ctx,
+		status.PositionBottom,
+		"#[bold]bold#[none] #[italics]italics#[none] #[underscore]underscore#[none] #[reverse]reverse#[none] #[fg=#EAA549]hex#[fg=default] #[bg=colour57]colour57#[align=centre]centre#[align=right]right",
+	)
+}
+
+func init() {
+	config := stories.Config{
+		Size: geom.DEFAULT_SIZE,
+	}
+	stories.Register("status/bottom", Bottom, config)
+	stories.Register("status/top", Top, config)
+	stories.Register("status/styles", Styles, config)
+}
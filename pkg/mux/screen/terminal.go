@@ -3,6 +3,7 @@ package screen
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/cfoust/cy/pkg/emu"
 	"github.com/cfoust/cy/pkg/geom/tty"
@@ -18,10 +19,71 @@ type Terminal struct {
 
 var _ Screen = (*Terminal)(nil)
 
+// OutputEvent is published when the process running in a Terminal writes
+// to it, but no more than once every outputInterval.
+type OutputEvent struct{}
+
+// outputInterval limits how often a Terminal publishes OutputEvents, since
+// busy processes can write thousands of times a second.
+const outputInterval = 250 * time.Millisecond
+
+// BellEvent is published when the process running in a Terminal rings the
+// bell.
+type BellEvent struct{}
+
+// TitleEvent is published when the process running in a Terminal changes
+// its title.
+type TitleEvent struct {
+	Title string
+}
+
+// NotificationEvent is published when the process running in a Terminal
+// sends a desktop notification.
+type NotificationEvent struct {
+	emu.Notification
+}
+
 func (t *Terminal) State() *tty.State {
 	return tty.Capture(t.terminal)
 }
 
+// Directory returns the working directory reported by the process running
+// in the terminal, if any.
+func (t *Terminal) Directory() string {
+	return t.terminal.Directory()
+}
+
+// DirectoryHost returns the host on which the directory returned by
+// Directory is located, or an empty string if it is on this machine.
+func (t *Terminal) DirectoryHost() string {
+	return t.terminal.DirectoryHost()
+}
+
+// WantsScroll reports whether the process running in the terminal handles
+// scrolling itself, either because it asked for mouse events or because it
+// is using the alternate screen, which has no scrollback.
+func (t *Terminal) WantsScroll() bool {
+	mode := t.terminal.Mode()
+	return mode&(emu.ModeMouseMask|emu.ModeAltScreen) != 0
+}
+
+// Capture returns a copy of the lines on the terminal's screen, preceded by
+// up to `history` of the most recent lines in its scrollback buffer.
+func (t *Terminal) Capture(history int) []emu.Line {
+	lines := t.terminal.RecentHistory(history)
+	for _, line := range t.terminal.Screen() {
+		lines = append(lines, append(emu.Line(nil), line...))
+	}
+
+	return lines
+}
+
+// ClearHistory removes all lines from the terminal's scrollback buffer.
+func (t *Terminal) ClearHistory() {
+	t.terminal.ClearHistory()
+	t.Notify()
+}
+
 func (t *Terminal) Resize(size Size) error {
 	t.terminal.Resize(size.C, size.R)
 
@@ -40,8 +102,15 @@ func (t *Terminal) Send(msg mux.Msg) {
 
 	switch msg := msg.(type) {
 	case taro.KeyMsg:
-		data, _ := taro.KeysToBytes(msg)
-		input = data
+		// Applications that requested the kitty keyboard protocol get
+		// disambiguated keys; everyone else gets the legacy encoding
+		if flags := t.terminal.KeyFlags(); flags != 0 {
+			input, _ = taro.KeysToKittyBytes(flags, msg)
+		} else if mode&emu.ModeAppCursor != 0 {
+			input, _ = taro.AppCursorKeysToBytes(msg)
+		} else {
+			input, _ = taro.KeysToBytes(msg)
+		}
 	case taro.MouseMsg:
 		switch mode & emu.ModeMouseMask {
 		case emu.ModeMouseX10:
@@ -71,9 +140,26 @@ func (t *Terminal) Send(msg mux.Msg) {
 	t.stream.Write(input)
 }
 
+// SendText writes `text` to the terminal's stream as though it had been
+// typed. If `paste` is true and the application has enabled bracketed paste
+// mode, the text is marked as pasted so that the application does not
+// interpret it as commands.
+func (t *Terminal) SendText(text string, paste bool) error {
+	data := []byte(text)
+	if paste && t.terminal.Mode()&emu.ModeBracketedPaste != 0 {
+		data = append([]byte("\x1b[200~"), data...)
+		data = append(data, []byte("\x1b[201~")...)
+	}
+
+	_, err := t.stream.Write(data)
+	return err
+}
+
 func (t *Terminal) poll(ctx context.Context) error {
 	// TODO(cfoust): 07/17/23 replace with io.Copy
 	buffer := make([]byte, 4096)
+	title := t.terminal.Title()
+	var lastOutput time.Time
 
 	for {
 		numBytes, err := t.stream.Read(buffer)
@@ -99,15 +185,46 @@ func (t *Terminal) poll(ctx context.Context) error {
 			return err
 		}
 
+		if t.terminal.Bell() {
+			t.Publish(BellEvent{})
+		}
+
+		if newTitle := t.terminal.Title(); newTitle != title {
+			title = newTitle
+			t.Publish(TitleEvent{Title: title})
+		}
+
+		for _, notification := range t.terminal.Notifications() {
+			t.Publish(NotificationEvent{notification})
+		}
+
 		// Let any clients know that this pane changed
-		t.Notify()
+		if now := time.Now(); now.Sub(lastOutput) >= outputInterval {
+			lastOutput = now
+			t.Publish(OutputEvent{})
+		} else {
+			t.Notify()
+		}
 	}
 }
 
-func NewTerminal(ctx context.Context, stream Stream, size Size) *Terminal {
+func NewTerminal(
+	ctx context.Context,
+	stream Stream,
+	size Size,
+	options ...emu.TerminalOption,
+) *Terminal {
+	options = append(
+		[]emu.TerminalOption{
+			emu.WithWriter(stream),
+			emu.WithSize(size),
+		},
+		options...,
+	)
+
 	terminal := &Terminal{
 		UpdatePublisher: mux.NewPublisher(),
-		terminal:        emu.New(emu.WithWriter(stream), emu.WithSize(size)),
+		terminal:        emu.New(options...),
 		stream:          stream,
 	}
 
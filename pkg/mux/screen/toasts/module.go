@@ -10,6 +10,7 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/sasha-s/go-deadlock"
 )
 
 type ToastLevel int
@@ -25,12 +26,33 @@ type Toast struct {
 	Level   ToastLevel
 }
 
-type popToast struct{}
+type popToast struct {
+	id int
+}
+
+// Dismiss removes the toast drawn at Position, if there is one.
+type Dismiss struct {
+	Position geom.Vec2
+}
+
+type toast struct {
+	Toast
+	id int
+}
+
+type region struct {
+	geom.Rect
+	id int
+}
 
 type Toaster struct {
+	deadlock.RWMutex
 	render *taro.Renderer
 
-	toasts []Toast
+	nextID int
+	toasts []toast
+	// where each toast was last drawn
+	regions []region
 }
 
 var _ taro.Model = (*Toaster)(nil)
@@ -42,23 +64,57 @@ func (t *Toaster) Init() taro.Cmd {
 func (t *Toaster) Update(msg tea.Msg) (taro.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case popToast:
-		if len(t.toasts) == 0 {
-			return t, nil
+		t.remove(msg.id)
+		return t, nil
+	case Dismiss:
+		if id, ok := t.at(msg.Position); ok {
+			t.remove(id)
 		}
-
-		t.toasts = t.toasts[1:]
 		return t, nil
 	case Toast:
-		t.toasts = append(t.toasts, msg)
+		t.nextID++
+		id := t.nextID
+		t.toasts = append(t.toasts, toast{Toast: msg, id: id})
 		return t, func() tea.Msg {
 			time.Sleep(10 * time.Second)
-			return popToast{}
+			return popToast{id: id}
 		}
 	}
 
 	return t, nil
 }
 
+func (t *Toaster) remove(id int) {
+	for i, toast := range t.toasts {
+		if toast.id != id {
+			continue
+		}
+
+		t.toasts = append(t.toasts[:i:i], t.toasts[i+1:]...)
+		return
+	}
+}
+
+func (t *Toaster) at(point geom.Vec2) (id int, ok bool) {
+	t.RLock()
+	defer t.RUnlock()
+
+	for _, region := range t.regions {
+		if region.Contains(point) {
+			return region.id, true
+		}
+	}
+
+	return 0, false
+}
+
+// Contains reports whether a toast is drawn at `point`. It is safe to call
+// from any goroutine.
+func (t *Toaster) Contains(point geom.Vec2) bool {
+	_, ok := t.at(point)
+	return ok
+}
+
 const TOAST_WIDTH = 45
 
 func (t *Toaster) View(state *tty.State) {
@@ -80,7 +136,9 @@ func (t *Toaster) View(state *tty.State) {
 		Width(TOAST_WIDTH)
 
 	var blocks []string
+	var regions []region
 	var style lipgloss.Style
+	row := pos.R
 	for _, toast := range t.toasts {
 		switch toast.Level {
 		case ToastLevelError:
@@ -97,9 +155,25 @@ func (t *Toaster) View(state *tty.State) {
 				Foreground(lipgloss.Color("14"))
 		}
 
-		blocks = append(blocks, style.Render(toast.Message))
+		block := style.Render(toast.Message)
+		blocks = append(blocks, block)
+		regions = append(regions, region{
+			Rect: geom.Rect{
+				Position: geom.Vec2{R: row, C: pos.C},
+				Size: geom.Vec2{
+					R: lipgloss.Height(block),
+					C: lipgloss.Width(block),
+				},
+			},
+			id: toast.id,
+		})
+		row += lipgloss.Height(block)
 	}
 
+	t.Lock()
+	t.regions = regions
+	t.Unlock()
+
 	t.render.RenderAt(
 		state.Image,
 		pos.R, pos.C,
@@ -107,8 +181,12 @@ func (t *Toaster) View(state *tty.State) {
 	)
 }
 
-func New(ctx context.Context) *taro.Program {
-	return taro.New(ctx, &Toaster{
+func NewToaster() *Toaster {
+	return &Toaster{
 		render: taro.NewRenderer(),
-	})
+	}
+}
+
+func New(ctx context.Context) *taro.Program {
+	return taro.New(ctx, NewToaster())
 }
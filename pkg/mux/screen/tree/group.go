@@ -29,6 +29,11 @@ func (g *Group) addNode(node Node) {
 	g.Lock()
 	g.children = append(g.children, node)
 	g.Unlock()
+
+	g.tree.Publish(NodeEvent{
+		Id:    node.Id(),
+		Event: CreateEvent{},
+	})
 }
 
 func (g *Group) removeNode(node Node) {
@@ -56,8 +61,9 @@ func (g *Group) NewPane(ctx context.Context, screen mux.Screen) *Pane {
 	metadata.params = g.params.NewChild()
 	g.addNode(pane)
 
+	// Removed panes stop publishing events
 	go func() {
-		updates := screen.Subscribe(ctx)
+		updates := screen.Subscribe(pane.Ctx())
 		for {
 			select {
 			case event := <-updates.Recv():
@@ -65,7 +71,7 @@ func (g *Group) NewPane(ctx context.Context, screen mux.Screen) *Pane {
 					Id:    metadata.Id(),
 					Event: event,
 				})
-			case <-ctx.Done():
+			case <-pane.Ctx().Done():
 				return
 			}
 		}
@@ -8,3 +8,11 @@ type NodeEvent struct {
 	Id    NodeID
 	Event events.Msg
 }
+
+// CreateEvent is published when a node is added to the tree.
+type CreateEvent struct{}
+
+// RenameEvent is published when a node's name changes.
+type RenameEvent struct {
+	Name string
+}
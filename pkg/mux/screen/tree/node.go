@@ -14,6 +14,7 @@ type NodeID = int32
 
 type metaData struct {
 	deadlock.RWMutex
+	tree   *Tree
 	id     NodeID
 	name   string
 	binds  *bind.BindScope
@@ -36,7 +37,6 @@ func (m *metaData) Params() *params.Parameters {
 
 func (m *metaData) SetName(name string) {
 	m.Lock()
-	defer m.Unlock()
 	m.name = strings.Map(func(r rune) rune {
 		if !unicode.IsPrint(r) || r == '/' {
 			return -1
@@ -44,6 +44,13 @@ func (m *metaData) SetName(name string) {
 
 		return r
 	}, name)
+	name = m.name
+	m.Unlock()
+
+	m.tree.Publish(NodeEvent{
+		Id:    m.id,
+		Event: RenameEvent{Name: name},
+	})
 }
 
 func (m *metaData) Binds() *bind.BindScope {
@@ -25,6 +25,7 @@ func (t *Tree) newMetadata() *metaData {
 
 	id := t.nextNodeID.Add(1)
 	node := &metaData{
+		tree:  t,
 		id:    id,
 		binds: bind.NewBindScope(),
 		name:  fmt.Sprintf("%d", id),
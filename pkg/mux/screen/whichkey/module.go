@@ -0,0 +1,179 @@
+package whichkey
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/cfoust/cy/pkg/geom"
+	"github.com/cfoust/cy/pkg/geom/tty"
+	"github.com/cfoust/cy/pkg/taro"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// Hint is a single key sequence that can follow the keys the user has
+// already typed.
+type Hint struct {
+	// The keys that remain to be typed
+	Keys        []string
+	Description string
+}
+
+// Show displays `Hints` for the given `Prefix` after `Delay` has elapsed,
+// replacing any hints that were already visible.
+type Show struct {
+	Prefix []string
+	Hints  []Hint
+	Delay  time.Duration
+}
+
+// Hide clears the hints from the screen.
+type Hide struct{}
+
+type reveal struct {
+	id int
+}
+
+// WhichKey renders a list of the key sequences that can complete a partial
+// key sequence along with their descriptions.
+type WhichKey struct {
+	render *taro.Renderer
+
+	// Incremented every time the hints change so that we can ignore
+	// reveals that are no longer relevant
+	id      int
+	visible bool
+	prefix  []string
+	hints   []Hint
+}
+
+var _ taro.Model = (*WhichKey)(nil)
+
+func (w *WhichKey) Init() taro.Cmd {
+	return nil
+}
+
+func (w *WhichKey) Update(msg tea.Msg) (taro.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case Show:
+		w.id++
+		w.prefix = msg.Prefix
+		w.hints = msg.Hints
+		w.visible = msg.Delay <= 0
+		if w.visible {
+			return w, nil
+		}
+
+		id := w.id
+		return w, func() tea.Msg {
+			time.Sleep(msg.Delay)
+			return reveal{id: id}
+		}
+	case reveal:
+		if msg.id == w.id {
+			w.visible = true
+		}
+		return w, nil
+	case Hide:
+		w.id++
+		w.visible = false
+		w.prefix = nil
+		w.hints = nil
+		return w, nil
+	}
+
+	return w, nil
+}
+
+const (
+	// The maximum width of a description, in cells
+	MAX_DESCRIPTION = 32
+	COLUMN_GAP      = 3
+)
+
+func (w *WhichKey) View(state *tty.State) {
+	if !w.visible || len(w.hints) == 0 {
+		return
+	}
+
+	size := state.Image.Size()
+
+	border := w.render.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("14")).
+		Background(lipgloss.Color("0")).
+		BorderBackground(lipgloss.Color("0")).
+		Padding(0, 1).
+		Width(size.C - 2)
+	keyStyle := w.render.NewStyle().
+		Foreground(lipgloss.Color("14")).
+		Background(lipgloss.Color("0"))
+	descriptionStyle := w.render.NewStyle().
+		Foreground(lipgloss.Color("7")).
+		Background(lipgloss.Color("0"))
+
+	keyWidth := 0
+	for _, hint := range w.hints {
+		keyWidth = geom.Max(keyWidth, runewidth.StringWidth(strings.Join(hint.Keys, " ")))
+	}
+
+	entries := make([]string, len(w.hints))
+	entryWidth := 0
+	for i, hint := range w.hints {
+		keys := strings.Join(hint.Keys, " ")
+		entries[i] = keyStyle.Render(
+			keys+strings.Repeat(" ", keyWidth-runewidth.StringWidth(keys)),
+		) + descriptionStyle.Render(
+			" "+runewidth.Truncate(hint.Description, MAX_DESCRIPTION, "…"),
+		)
+		entryWidth = geom.Max(entryWidth, lipgloss.Width(entries[i]))
+	}
+
+	// The border and padding take up four columns and the border and
+	// title take up three rows
+	innerWidth := size.C - 4
+	numColumns := geom.Max(1, (innerWidth+COLUMN_GAP)/(entryWidth+COLUMN_GAP))
+	numRows := (len(entries) + numColumns - 1) / numColumns
+	numRows = geom.Max(1, geom.Min(numRows, size.R-3))
+
+	columnStyle := descriptionStyle.Copy().Width(entryWidth)
+	var columns []string
+	for start := 0; start < len(entries) && len(columns) < numColumns; start += numRows {
+		end := geom.Min(start+numRows, len(entries))
+		column := columnStyle.Render(
+			lipgloss.JoinVertical(lipgloss.Left, entries[start:end]...),
+		)
+
+		if len(columns) > 0 {
+			column = lipgloss.JoinHorizontal(
+				lipgloss.Top,
+				descriptionStyle.Render(strings.Repeat(" ", COLUMN_GAP)),
+				column,
+			)
+		}
+
+		columns = append(columns, column)
+	}
+
+	title := keyStyle.Copy().Bold(true).Render(strings.Join(w.prefix, " "))
+	box := border.Render(lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		lipgloss.JoinHorizontal(lipgloss.Top, columns...),
+	))
+
+	w.render.RenderAt(
+		state.Image,
+		size.R-lipgloss.Height(box), 0,
+		box,
+	)
+}
+
+func New(ctx context.Context) *taro.Program {
+	return taro.New(ctx, &WhichKey{
+		render: taro.NewRenderer(),
+	})
+}
@@ -0,0 +1,88 @@
+package whichkey
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cfoust/cy/pkg/geom"
+	"github.com/cfoust/cy/pkg/geom/tty"
+	"github.com/cfoust/cy/pkg/taro"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newWhichKey() *WhichKey {
+	return &WhichKey{render: taro.NewRenderer()}
+}
+
+// render returns the text `w` draws on a screen of the given size.
+func render(w *WhichKey, size geom.Vec2) string {
+	state := tty.New(size)
+	w.View(state)
+
+	var lines []string
+	for _, line := range state.Image {
+		lines = append(lines, line.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestReveal(t *testing.T) {
+	w := newWhichKey()
+	show := Show{
+		Prefix: []string{"ctrl+a"},
+		Hints: []Hint{
+			{Keys: []string{"c"}, Description: "create"},
+			{Keys: []string{"/[0-9]/"}, Description: "jump"},
+		},
+	}
+
+	// Without a delay the hints are visible immediately
+	_, cmd := w.Update(show)
+	require.Nil(t, cmd)
+	require.True(t, w.visible)
+
+	view := render(w, geom.Vec2{R: 10, C: 40})
+	for _, text := range []string{"ctrl+a", "c", "create", "/[0-9]/", "jump"} {
+		require.Contains(t, view, text)
+	}
+
+	// Otherwise they appear once the delay has elapsed
+	show.Delay = time.Millisecond
+	_, cmd = w.Update(show)
+	require.NotNil(t, cmd)
+	require.False(t, w.visible)
+	require.Equal(t, "", strings.TrimSpace(render(w, geom.Vec2{R: 10, C: 40})))
+
+	w.Update(cmd())
+	require.True(t, w.visible)
+}
+
+func TestHide(t *testing.T) {
+	w := newWhichKey()
+	show := Show{
+		Prefix: []string{"ctrl+a"},
+		Hints:  []Hint{{Keys: []string{"c"}, Description: "create"}},
+		Delay:  time.Millisecond,
+	}
+
+	// Hints that were hidden before the delay elapsed never appear
+	_, cmd := w.Update(show)
+	w.Update(Hide{})
+	w.Update(cmd())
+	require.False(t, w.visible)
+	require.Nil(t, w.hints)
+
+	// Neither does a reveal for hints that were since replaced
+	_, stale := w.Update(show)
+	_, cmd = w.Update(show)
+	w.Update(stale())
+	require.False(t, w.visible)
+	w.Update(cmd())
+	require.True(t, w.visible)
+
+	w.Update(Hide{})
+	require.False(t, w.visible)
+	require.Equal(t, "", strings.TrimSpace(render(w, geom.Vec2{R: 10, C: 40})))
+}
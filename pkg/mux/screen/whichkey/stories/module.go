@@ -0,0 +1,37 @@
+package stories
+
+import (
+	"context"
+
+	"github.com/cfoust/cy/pkg/geom"
+	"github.com/cfoust/cy/pkg/mux"
+	"github.com/cfoust/cy/pkg/mux/screen/whichkey"
+	"github.com/cfoust/cy/pkg/stories"
+)
+
+var WhichKey stories.InitFunc = func(ctx context.Context) mux.Screen {
+	w := whichkey.New(ctx)
+	w.Send(whichkey.Show{
+		Prefix: []string{"ctrl+a"},
+		Hints: []whichkey.Hint{
+			{Keys: []string{"+"}, Description: "decrease margins by 5 columns"},
+			{Keys: []string{"-"}, Description: "increase margins by 5 columns"},
+			{Keys: []string{"1"}, Description: "set size to 80 columns"},
+			{Keys: []string{"2"}, Description: "set size to 160 columns"},
+			{Keys: []string{";"}, Description: "jump to a pane"},
+			{Keys: []string{"ctrl+p"}, Description: "open command palette"},
+			{Keys: []string{"d"}, Description: "Detach from the `cy` server."},
+			{Keys: []string{"j"}, Description: "create a new shell"},
+			{Keys: []string{"k"}, Description: "jump to a project"},
+			{Keys: []string{"r", "r"}, Description: "switch to a random frame"},
+			{Keys: []string{"x"}, Description: "kill the current pane"},
+		},
+	})
+	return w
+}
+
+func init() {
+	stories.Register("which-key", WhichKey, stories.Config{
+		Size: geom.DEFAULT_SIZE,
+	})
+}
@@ -29,6 +29,11 @@ const (
 	CmdStatusFailed
 )
 
+// ExitEvent is published every time the command exits.
+type ExitEvent struct {
+	Code int
+}
+
 type Cmd struct {
 	deadlock.RWMutex
 
@@ -37,6 +42,7 @@ type Cmd struct {
 	size    Size
 
 	statusUpdates *util.Publisher[CmdStatus]
+	exits         *util.Publisher[ExitEvent]
 
 	ptmx *os.File
 	proc *os.Process
@@ -80,6 +86,18 @@ func (c *Cmd) Path() (string, error) {
 	return dir.ForPid(proc.Pid)
 }
 
+// Pid returns the process ID of the command that is currently running.
+func (c *Cmd) Pid() (int, error) {
+	c.RLock()
+	proc := c.proc
+	c.RUnlock()
+	if proc == nil {
+		return 0, fmt.Errorf("process not yet started")
+	}
+
+	return proc.Pid, nil
+}
+
 func (c *Cmd) setStatus(status CmdStatus) {
 	c.Lock()
 	c.status = status
@@ -121,7 +139,15 @@ func (c *Cmd) runPty(ctx context.Context) (chan error, error) {
 		c.Unlock()
 
 		defer fd.Close()
-		shellDone <- cmd.Wait()
+		err = cmd.Wait()
+
+		code := -1
+		if cmd.ProcessState != nil {
+			code = cmd.ProcessState.ExitCode()
+		}
+		c.exits.Publish(ExitEvent{Code: code})
+
+		shellDone <- err
 	}()
 
 	err := <-started
@@ -247,6 +273,12 @@ func (c *Cmd) Subscribe(ctx context.Context) *util.Subscriber[CmdStatus] {
 	return c.statusUpdates.Subscribe(ctx)
 }
 
+// SubscribeExits returns a Subscriber that receives an ExitEvent every time
+// the command exits.
+func (c *Cmd) SubscribeExits(ctx context.Context) *util.Subscriber[ExitEvent] {
+	return c.exits.Subscribe(ctx)
+}
+
 func (c *Cmd) waitHealthy(ctx context.Context) error {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -281,6 +313,7 @@ func NewCmd(ctx context.Context, options CmdOptions, size Size) (*Cmd, error) {
 		options:       options,
 		size:          size,
 		statusUpdates: util.NewPublisher[CmdStatus](),
+		exits:         util.NewPublisher[ExitEvent](),
 	}
 
 	go cmd.spin(ctx)
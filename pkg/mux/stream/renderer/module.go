@@ -2,6 +2,7 @@ package renderer
 
 import (
 	"context"
+	"fmt"
 	"io"
 
 	"github.com/cfoust/cy/pkg/emu"
@@ -58,6 +59,23 @@ func (r *Renderer) Write(data []byte) (n int, err error) {
 	return len(data), nil
 }
 
+// Notify asks the destination terminal to show a desktop notification. OSC
+// 777 is used if the notification has a title and OSC 9 otherwise.
+func (r *Renderer) Notify(notification emu.Notification) error {
+	var err error
+	if notification.Title == "" {
+		_, err = fmt.Fprintf(r.w, "\x1b]9;%s\x07", notification.Body)
+	} else {
+		_, err = fmt.Fprintf(
+			r.w,
+			"\x1b]777;notify;%s;%s\x07",
+			notification.Title,
+			notification.Body,
+		)
+	}
+	return err
+}
+
 func (r *Renderer) Read(p []byte) (n int, err error) {
 	return r.r.Read(p)
 }
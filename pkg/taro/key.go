@@ -32,6 +32,8 @@ import (
 	"fmt"
 	"io"
 	"regexp"
+	"strings"
+	"unicode"
 	"unicode/utf8"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -91,6 +93,18 @@ type Key struct {
 	Type  KeyType
 	Runes []rune
 	Alt   bool
+
+	// Ctrl, Shift, and Super are only set for key combinations that
+	// cannot be represented by Type alone, such as ctrl+i (which is
+	// otherwise indistinguishable from tab.) Only terminals that support
+	// the kitty keyboard protocol report these.
+	Ctrl  bool
+	Shift bool
+	Super bool
+
+	// Event is the type of the key event. Terminals that do not support
+	// the kitty keyboard protocol only send presses.
+	Event KeyEvent
 }
 
 // String returns a friendly string representation for a key. It's safe (and
@@ -103,6 +117,15 @@ func (k Key) String() (str string) {
 	if k.Alt {
 		str += "alt+"
 	}
+	if k.Ctrl {
+		str += "ctrl+"
+	}
+	if k.Shift {
+		str += "shift+"
+	}
+	if k.Super {
+		str += "super+"
+	}
 	if k.Type == KeyRunes {
 		str += string(k.Runes)
 		return str
@@ -141,9 +164,17 @@ func (k KeyType) String() (str string) {
 }
 
 // KeysToMsg translates human-readable key specifiers (such as "ctrl+a", "up",
-// etc) into KeyMsg events. Unrecognized strings are represented as KeyRunes.
+// etc) into KeyMsg events. Any specifier can be prefixed with "alt+".
+// Unrecognized strings are represented as KeyRunes.
 func KeysToMsg(keys ...string) (msgs []KeyMsg) {
 	for _, key := range keys {
+		if rest, ok := strings.CutPrefix(key, "alt+"); ok && len(rest) > 0 {
+			msg := KeysToMsg(rest)[0]
+			msg.Alt = true
+			msgs = append(msgs, msg)
+			continue
+		}
+
 		if _type, ok := keyRefs[key]; ok {
 			msgs = append(msgs, KeyMsg{
 				Type: _type,
@@ -151,6 +182,13 @@ func KeysToMsg(keys ...string) (msgs []KeyMsg) {
 			continue
 		}
 
+		// Modifiers that cannot be represented by a key's type alone, as
+		// produced by Key.String()
+		if msg, ok := keyWithModifier(key); ok {
+			msgs = append(msgs, msg)
+			continue
+		}
+
 		msgs = append(msgs, KeyMsg{
 			Type:  KeyRunes,
 			Runes: []rune(key),
@@ -159,12 +197,52 @@ func KeysToMsg(keys ...string) (msgs []KeyMsg) {
 	return
 }
 
+func keyWithModifier(key string) (msg KeyMsg, ok bool) {
+	for _, modifier := range []string{"ctrl+", "shift+", "super+"} {
+		rest, found := strings.CutPrefix(key, modifier)
+		if !found || len(rest) == 0 {
+			continue
+		}
+
+		msg = KeysToMsg(rest)[0]
+		switch modifier {
+		case "ctrl+":
+			msg.Ctrl = true
+		case "shift+":
+			msg.Shift = true
+		case "super+":
+			msg.Super = true
+		}
+		return msg, true
+	}
+	return
+}
+
+// KeysToBytes encodes keys using the legacy encoding most terminals
+// understand. Key releases and modifiers that cannot be represented are
+// dropped.
 func KeysToBytes(keys ...KeyMsg) (data []byte, err error) {
 	for _, key := range keys {
+		if key.Event == KeyEventRelease {
+			continue
+		}
+
 		switch key.Type {
 		case KeySpace:
+			if key.Alt {
+				data = append(data, '\x1b')
+			}
 			data = append(data, []byte(" ")...)
 		case KeyRunes:
+			if key.Alt {
+				data = append(data, '\x1b')
+			}
+
+			if r := key.Runes; key.Ctrl && len(r) == 1 && r[0] >= '@' && r[0] <= '~' {
+				data = append(data, byte(unicode.ToUpper(r[0]))&0x1f)
+				continue
+			}
+
 			data = append(data, []byte(string(key.Runes))...)
 		default:
 			if seq, ok := inverseSequences[keyLookup{
@@ -182,6 +260,35 @@ func KeysToBytes(keys ...KeyMsg) (data []byte, err error) {
 	return
 }
 
+// appCursorKeys are the sequences for cursor keys when the application has
+// enabled application cursor mode (DECCKM).
+var appCursorKeys = map[KeyType][]byte{
+	KeyUp:    []byte("\x1bOA"),
+	KeyDown:  []byte("\x1bOB"),
+	KeyRight: []byte("\x1bOC"),
+	KeyLeft:  []byte("\x1bOD"),
+	KeyHome:  []byte("\x1bOH"),
+	KeyEnd:   []byte("\x1bOF"),
+}
+
+// AppCursorKeysToBytes is like KeysToBytes, but encodes unmodified cursor
+// keys the way applications expect in application cursor mode (DECCKM).
+func AppCursorKeysToBytes(keys ...KeyMsg) (data []byte, err error) {
+	for _, key := range keys {
+		if seq, ok := appCursorKeys[key.Type]; ok && !key.Alt && key.Event != KeyEventRelease {
+			data = append(data, seq...)
+			continue
+		}
+
+		encoded, err := KeysToBytes(key)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, encoded...)
+	}
+	return
+}
+
 // unknownInputByteMsg is reported by the input reader when an invalid
 // utf-8 byte is detected on the input. Currently, it is not handled
 // further by bubbletea. However, having this event makes it possible
@@ -238,10 +345,16 @@ func DetectOneMsg(b []byte) (w int, msg Msg) {
 		return 6, MouseMsg(parseX10MouseEvent(b))
 	}
 
+	// Detect keys encoded with the kitty keyboard protocol.
+	var foundSeq bool
+	foundSeq, w, msg = detectKittySequence(b)
+	if foundSeq {
+		return
+	}
+
 	// Detect escape sequence and control characters other than NUL,
 	// possibly with an escape character in front to mark the Alt
 	// modifier.
-	var foundSeq bool
 	foundSeq, w, msg = detectSequence(b)
 	if foundSeq {
 		return
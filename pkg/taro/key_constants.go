@@ -1,5 +1,7 @@
 package taro
 
+import "strings"
+
 // Control keys. We could do this with an iota, but the values are very
 // specific, so we set the values explicitly to avoid any confusion.
 //
@@ -517,14 +519,55 @@ type keyLookup struct {
 	Alt  bool
 }
 
+// isPreferredSequence reports whether `a` should be used instead of `b` to
+// encode a key that can be expressed with either sequence. Modifiers encoded
+// xterm-style win over ESC-prefixed ones and the Linux console's function
+// keys lose to everything else, so that the result matches what xterm sends
+// in normal mode.
+func isPreferredSequence(a, b string) bool {
+	rank := func(seq string) (score int) {
+		if !strings.Contains(seq, ";") {
+			score += 4
+		}
+		if strings.HasPrefix(seq, "\x1b\x1b") {
+			score += 2
+		}
+		if strings.HasPrefix(seq, "\x1b[[") {
+			score++
+		}
+		return
+	}
+
+	if rankA, rankB := rank(a), rank(b); rankA != rankB {
+		return rankA < rankB
+	}
+
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+
+	// Prefer CSI sequences ("\x1b[") over SS3 ones ("\x1bO")
+	if isCSIA, isCSIB := strings.HasPrefix(a, "\x1b["), strings.HasPrefix(b, "\x1b["); isCSIA != isCSIB {
+		return isCSIA
+	}
+
+	return a < b
+}
+
 // inverseSequences is a mapping from a Key to its byte sequence.
 var inverseSequences = func() map[keyLookup][]byte {
 	s := map[keyLookup][]byte{}
 	for str, key := range extSequences {
-		s[keyLookup{
+		lookup := keyLookup{
 			Type: key.Type,
 			Alt:  key.Alt,
-		}] = []byte(str)
+		}
+
+		if existing, ok := s[lookup]; ok && !isPreferredSequence(str, string(existing)) {
+			continue
+		}
+
+		s[lookup] = []byte(str)
 	}
 	return s
 }()
@@ -3,6 +3,8 @@ package taro
 import (
 	"testing"
 
+	"github.com/cfoust/cy/pkg/emu"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -16,6 +18,63 @@ func TestKeysToMsg(t *testing.T) {
 			Type: KeyCtrlA,
 		},
 	}, KeysToMsg("test", "ctrl+a"))
+
+	assert.Equal(t, []KeyMsg{
+		{
+			Type: KeyUp,
+			Alt:  true,
+		},
+		{
+			Type:  KeyRunes,
+			Runes: []rune("x"),
+			Alt:   true,
+		},
+	}, KeysToMsg("alt+up", "alt+x"))
+
+	assert.Equal(t, []KeyMsg{
+		{
+			Type:  KeyRunes,
+			Runes: []rune("i"),
+			Ctrl:  true,
+		},
+		{
+			Type:  KeyEnter,
+			Shift: true,
+		},
+	}, KeysToMsg("ctrl+i", "shift+enter"))
+
+	// Keys round-trip through Key.String()
+	for _, key := range []KeyMsg{
+		{Type: KeyRunes, Runes: []rune("x"), Alt: true, Shift: true},
+		{Type: KeyUp, Super: true},
+		{Type: KeyCtrlA},
+	} {
+		assert.Equal(t, []KeyMsg{key}, KeysToMsg(key.String()))
+	}
+}
+
+func TestAppCursorKeysToBytes(t *testing.T) {
+	bytes, err := AppCursorKeysToBytes(KeysToMsg("up", "a", "end")...)
+	assert.NoError(t, err)
+	assert.Equal(t, "\x1bOAa\x1bOF", string(bytes))
+}
+
+func TestKeysToBytesXterm(t *testing.T) {
+	// Keys with several encodings always use the one xterm sends
+	for keys, expected := range map[string]string{
+		"up":        "\x1b[A",
+		"home":      "\x1b[H",
+		"end":       "\x1b[F",
+		"f1":        "\x1bOP",
+		"f5":        "\x1b[15~",
+		"shift+up":  "\x1b[1;2A",
+		"alt+up":    "\x1b[1;3A",
+		"ctrl+home": "\x1b[1;5H",
+	} {
+		bytes, err := KeysToBytes(KeysToMsg(keys)...)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, string(bytes), keys)
+	}
 }
 
 func TestKeysToBytes(t *testing.T) {
@@ -69,3 +128,77 @@ func TestMouse(t *testing.T) {
 	testMouseInput(t, "\u001b[MCu,")
 	testMouseInput(t, "\u001b[MbM<")
 }
+
+func TestKitty(t *testing.T) {
+	for input, expected := range map[string]Key{
+		"\x1b[105;5u":   {Type: KeyRunes, Runes: []rune("i"), Ctrl: true},
+		"\x1b[97;5u":    {Type: KeyCtrlA},
+		"\x1b[97;3u":    {Type: KeyRunes, Runes: []rune("a"), Alt: true},
+		"\x1b[97:65;2u": {Type: KeyRunes, Runes: []rune("A")},
+		"\x1b[97;1:3u":  {Type: KeyRunes, Runes: []rune("a"), Event: KeyEventRelease},
+		"\x1b[27u":      {Type: KeyEscape},
+		"\x1b[13;5u":    {Type: KeyEnter, Ctrl: true},
+		"\x1b[9;2u":     {Type: KeyShiftTab},
+		"\x1b[1;5:2A":   {Type: KeyCtrlUp, Event: KeyEventRepeat},
+		"\x1b[3;1:3~":   {Type: KeyDelete, Event: KeyEventRelease},
+	} {
+		_, msg := DetectOneMsg([]byte(input))
+		assert.Equal(t, KeyMsg(expected), msg, "%q", input)
+	}
+
+	_, msg := DetectOneMsg([]byte("\x1b[?11u"))
+	assert.Equal(t, KeyFlagsMsg(11), msg)
+
+	assert.Equal(t, "ctrl+i", Key{Type: KeyRunes, Runes: []rune("i"), Ctrl: true}.String())
+}
+
+func TestKittyEncode(t *testing.T) {
+	ctrlI := KeyMsg{Type: KeyRunes, Runes: []rune("i"), Ctrl: true}
+	release := KeyMsg{Type: KeyRunes, Runes: []rune("a"), Event: KeyEventRelease}
+
+	for _, test := range []struct {
+		flags    emu.KeyFlag
+		key      KeyMsg
+		expected string
+	}{
+		{0, ctrlI, "\t"},
+		{0, release, ""},
+		{0, KeyMsg{Type: KeyRunes, Runes: []rune("a"), Alt: true}, "\x1ba"},
+		{emu.KeyDisambiguate, ctrlI, "\x1b[105;5u"},
+		{emu.KeyDisambiguate, release, ""},
+		{emu.KeyDisambiguate, KeyMsg{Type: KeyRunes, Runes: []rune("a")}, "a"},
+		{emu.KeyDisambiguate, KeyMsg{Type: KeyCtrlA}, "\x1b[97;5u"},
+		{emu.KeyDisambiguate, KeyMsg{Type: KeyEscape}, "\x1b[27u"},
+		{emu.KeyDisambiguate, KeyMsg{Type: KeyEnter}, "\r"},
+		{emu.KeyDisambiguate, KeyMsg{Type: KeyUp}, "\x1b[A"},
+		{emu.KeyDisambiguate, KeyMsg{Type: KeyCtrlUp}, "\x1b[1;5A"},
+		{emu.KeyDisambiguate | emu.KeyReportEvents, release, "\x1b[97;1:3u"},
+		{emu.KeyReportAll | emu.KeyReportAlternates, KeyMsg{Type: KeyRunes, Runes: []rune("A")}, "\x1b[97:65;2u"},
+	} {
+		data, err := KeysToKittyBytes(test.flags, test.key)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, string(data), "%+v", test)
+	}
+}
+
+func TestMouseKey(t *testing.T) {
+	_, msg := DetectOneMsg([]byte("\x1b[M :;"))
+	key, ok := MouseEvent(msg.(MouseMsg)).Key()
+	assert.True(t, ok)
+	assert.Equal(t, "mouse-left", key)
+
+	key, ok = MouseEvent{
+		Type:   MousePress,
+		Button: MouseWheelUp,
+		Down:   true,
+		Alt:    true,
+	}.Key()
+	assert.True(t, ok)
+	assert.Equal(t, "alt+wheel-up", key)
+
+	// Releases and motion cannot be bound
+	_, ok = MouseEvent{Type: MousePress, Button: MouseLeft}.Key()
+	assert.False(t, ok)
+	_, ok = MouseEvent{Type: MouseMotion, Button: MouseLeft, Down: true}.Key()
+	assert.False(t, ok)
+}
@@ -0,0 +1,545 @@
+package taro
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/cfoust/cy/pkg/emu"
+)
+
+// This file implements decoding and encoding of keys using the kitty
+// keyboard protocol, which is described here:
+// https://sw.kovidgoyal.net/kitty/keyboard-protocol/
+
+// KeyEvent distinguishes between the types of key events reported by the
+// kitty keyboard protocol. Legacy terminals only report presses.
+type KeyEvent int
+
+const (
+	KeyEventPress KeyEvent = iota
+	KeyEventRepeat
+	KeyEventRelease
+)
+
+// KeyFlagsMsg is sent when the terminal responds to a query for the
+// current kitty keyboard protocol flags (CSI ? u). Terminals that do not
+// support the protocol do not respond at all.
+type KeyFlagsMsg emu.KeyFlag
+
+// The sequences sent to the outer terminal to negotiate the protocol.
+const (
+	// QueryKeyFlags asks the terminal for its keyboard flags.
+	QueryKeyFlags = "\x1b[?u"
+	// PopKeyFlags restores the flags that were active before the last push.
+	PopKeyFlags = "\x1b[<u"
+)
+
+// PushKeyFlags returns the sequence that pushes `flags` onto the
+// terminal's keyboard flag stack.
+func PushKeyFlags(flags emu.KeyFlag) string {
+	return fmt.Sprintf("\x1b[>%du", flags)
+}
+
+const (
+	kittyShift = 1 << iota
+	kittyAlt
+	kittyCtrl
+	kittySuper
+	kittyHyper
+	kittyMeta
+)
+
+var (
+	kittyFlagsRe = regexp.MustCompile(`^\x1b\[\?(\d*)u`)
+	kittyKeyRe   = regexp.MustCompile(`^\x1b\[([0-9:;]*)([u~ABCDFHPQS])`)
+)
+
+// kittyFunctional maps the key codes used in CSI u sequences to the
+// functional keys they represent.
+var kittyFunctional = map[int]KeyType{
+	9:   KeyTab,
+	13:  KeyEnter,
+	27:  KeyEscape,
+	127: KeyBackspace,
+}
+
+// kittyTilde maps the numbers used in CSI ~ sequences to keys.
+var kittyTilde = map[int]KeyType{
+	2:  KeyInsert,
+	3:  KeyDelete,
+	5:  KeyPgUp,
+	6:  KeyPgDown,
+	7:  KeyHome,
+	8:  KeyEnd,
+	11: KeyF1,
+	12: KeyF2,
+	13: KeyF3,
+	14: KeyF4,
+	15: KeyF5,
+	17: KeyF6,
+	18: KeyF7,
+	19: KeyF8,
+	20: KeyF9,
+	21: KeyF10,
+	23: KeyF11,
+	24: KeyF12,
+}
+
+// kittyLetter maps the final byte of legacy-style CSI sequences to keys.
+var kittyLetter = map[byte]KeyType{
+	'A': KeyUp,
+	'B': KeyDown,
+	'C': KeyRight,
+	'D': KeyLeft,
+	'F': KeyEnd,
+	'H': KeyHome,
+	'P': KeyF1,
+	'Q': KeyF2,
+	'S': KeyF4,
+}
+
+// kittyF13 is the key code of F13 in the kitty protocol. F13-F35 are
+// sequential from there.
+const kittyF13 = 57376
+
+// modifiedKey describes the KeyTypes that represent a key with modifiers
+// held, if there are any.
+type modifiedKey struct {
+	ctrl, shift, ctrlShift KeyType
+}
+
+var modifiedKeys = map[KeyType]modifiedKey{
+	KeyUp:     {KeyCtrlUp, KeyShiftUp, KeyCtrlShiftUp},
+	KeyDown:   {KeyCtrlDown, KeyShiftDown, KeyCtrlShiftDown},
+	KeyLeft:   {KeyCtrlLeft, KeyShiftLeft, KeyCtrlShiftLeft},
+	KeyRight:  {KeyCtrlRight, KeyShiftRight, KeyCtrlShiftRight},
+	KeyHome:   {KeyCtrlHome, KeyShiftHome, KeyCtrlShiftHome},
+	KeyEnd:    {KeyCtrlEnd, KeyShiftEnd, KeyCtrlShiftEnd},
+	KeyPgUp:   {ctrl: KeyCtrlPgUp},
+	KeyPgDown: {ctrl: KeyCtrlPgDown},
+	KeyTab:    {shift: KeyShiftTab},
+}
+
+// baseKey is a key without the modifiers implied by its KeyType.
+type baseKey struct {
+	base        KeyType
+	ctrl, shift bool
+}
+
+// baseKeys is the inverse of modifiedKeys.
+var baseKeys = func() map[KeyType]baseKey {
+	m := make(map[KeyType]baseKey)
+	for base, modified := range modifiedKeys {
+		if modified.ctrl != 0 {
+			m[modified.ctrl] = baseKey{base, true, false}
+		}
+		if modified.shift != 0 {
+			m[modified.shift] = baseKey{base, false, true}
+		}
+		if modified.ctrlShift != 0 {
+			m[modified.ctrlShift] = baseKey{base, true, true}
+		}
+	}
+	return m
+}()
+
+// ctrlRunes maps the runes that, when pressed with ctrl, have an
+// unambiguous legacy representation to the KeyType for that
+// representation. Notably ctrl+i, ctrl+m, and ctrl+[ are absent, since
+// they are otherwise indistinguishable from tab, enter, and escape.
+var ctrlRunes = func() map[rune]KeyType {
+	m := map[rune]KeyType{
+		' ':  keyNUL,
+		'\\': keyFS,
+		']':  keyGS,
+	}
+	for r := 'a'; r <= 'z'; r++ {
+		if r == 'i' || r == 'm' {
+			continue
+		}
+		m[r] = KeyCtrlA + KeyType(r-'a')
+	}
+	return m
+}()
+
+// controlRunes is the inverse of ctrlRunes, with the addition of the
+// control characters that do not have a kitty equivalent.
+var controlRunes = func() map[KeyType]rune {
+	m := map[KeyType]rune{
+		keyRS: '^',
+		keyUS: '_',
+		keyLF: 'j',
+	}
+	for r, key := range ctrlRunes {
+		m[key] = r
+	}
+	return m
+}()
+
+// parseKittyParams splits the parameters of a CSI sequence into fields
+// (separated by ;) and subfields (separated by :).
+func parseKittyParams(params string) (fields [][]int) {
+	for _, field := range strings.Split(params, ";") {
+		var values []int
+		for _, sub := range strings.Split(field, ":") {
+			value, _ := strconv.Atoi(sub)
+			values = append(values, value)
+		}
+		fields = append(fields, values)
+	}
+	return
+}
+
+func getParam(fields [][]int, i, j, def int) int {
+	if i >= len(fields) || j >= len(fields[i]) || fields[i][j] == 0 {
+		return def
+	}
+	return fields[i][j]
+}
+
+// detectKittySequence attempts to parse a key or response that can only
+// be sent by a terminal that supports the kitty keyboard protocol.
+func detectKittySequence(b []byte) (hasSeq bool, width int, msg Msg) {
+	if loc := kittyFlagsRe.FindSubmatchIndex(b); loc != nil {
+		flags, _ := strconv.Atoi(string(b[loc[2]:loc[3]]))
+		return true, loc[1], KeyFlagsMsg(flags)
+	}
+
+	loc := kittyKeyRe.FindSubmatchIndex(b)
+	if loc == nil {
+		return false, 0, nil
+	}
+
+	params := string(b[loc[2]:loc[3]])
+	final := b[loc[4]]
+
+	// Legacy-style sequences are only unique to the kitty protocol when
+	// they include event types, which are separated by a colon.
+	if final != 'u' && !strings.Contains(params, ":") {
+		return false, 0, nil
+	}
+
+	width = loc[1]
+	fields := parseKittyParams(params)
+	mods := getParam(fields, 1, 0, 1) - 1
+	event := KeyEvent(getParam(fields, 1, 1, 1) - 1)
+
+	var (
+		key Key
+		ok  bool
+	)
+	switch final {
+	case 'u':
+		code := getParam(fields, 0, 0, 0)
+		shifted := getParam(fields, 0, 1, 0)
+		if _type, isFunctional := kittyFunctional[code]; isFunctional {
+			key, ok = decodeFunctional(_type, mods), true
+		} else if code >= kittyF13 && code <= kittyF13+int(KeyF13-KeyF20) {
+			key, ok = decodeFunctional(KeyF13-KeyType(code-kittyF13), mods), true
+		} else if (code > 0 && code < 0xE000) || code > 0xF8FF {
+			// Codes in the private use area that we have not
+			// handled above (such as the keypad and modifier
+			// keys) are not supported
+			key, ok = decodeRune(rune(code), rune(shifted), mods), true
+		}
+	case '~':
+		var _type KeyType
+		_type, ok = kittyTilde[getParam(fields, 0, 0, 0)]
+		key = decodeFunctional(_type, mods)
+	default:
+		var _type KeyType
+		_type, ok = kittyLetter[final]
+		key = decodeFunctional(_type, mods)
+	}
+
+	if !ok {
+		return true, width, unknownCSISequenceMsg(b[:width])
+	}
+
+	key.Event = event
+	return true, width, KeyMsg(key)
+}
+
+func decodeFunctional(_type KeyType, mods int) Key {
+	key := Key{
+		Type:  _type,
+		Alt:   mods&(kittyAlt|kittyMeta) != 0,
+		Ctrl:  mods&kittyCtrl != 0,
+		Shift: mods&kittyShift != 0,
+		Super: mods&kittySuper != 0,
+	}
+
+	modified, ok := modifiedKeys[_type]
+	if !ok {
+		return key
+	}
+
+	switch {
+	case key.Ctrl && key.Shift && modified.ctrlShift != 0:
+		key.Type = modified.ctrlShift
+		key.Ctrl, key.Shift = false, false
+	case key.Ctrl && !key.Shift && modified.ctrl != 0:
+		key.Type = modified.ctrl
+		key.Ctrl = false
+	case key.Shift && !key.Ctrl && modified.shift != 0:
+		key.Type = modified.shift
+		key.Shift = false
+	}
+
+	return key
+}
+
+func decodeRune(code, shifted rune, mods int) Key {
+	key := Key{
+		Type:  KeyRunes,
+		Runes: []rune{code},
+		Alt:   mods&(kittyAlt|kittyMeta) != 0,
+		Ctrl:  mods&kittyCtrl != 0,
+		Shift: mods&kittyShift != 0,
+		Super: mods&kittySuper != 0,
+	}
+
+	if key.Ctrl && !key.Shift && !key.Super {
+		if _type, ok := ctrlRunes[code]; ok {
+			key.Type = _type
+			key.Runes = nil
+			key.Ctrl = false
+			return key
+		}
+	}
+
+	// If shift is the only modifier that affects the key's meaning, we
+	// represent it with the text the key would produce
+	if key.Shift && !key.Ctrl && !key.Super {
+		switch {
+		case shifted != 0:
+			key.Runes = []rune{shifted}
+			key.Shift = false
+		case unicode.IsLetter(code):
+			key.Runes = []rune{unicode.ToUpper(code)}
+			key.Shift = false
+		}
+	}
+
+	if len(key.Runes) == 1 && key.Runes[0] == ' ' && !key.Ctrl && !key.Shift && !key.Super {
+		key.Type = KeySpace
+		key.Runes = spaceRunes
+	}
+
+	return key
+}
+
+// kittyCode is a key decomposed into the parts necessary for encoding it
+// with the kitty protocol.
+type kittyCode struct {
+	// code is the unicode codepoint of the key, or the number of a
+	// functional key.
+	code int
+	// shifted is the shifted version of the key, if it is known.
+	shifted rune
+	// final is the final byte of the CSI sequence.
+	final byte
+	// text is true if the key would generate text when pressed without
+	// modifiers.
+	text bool
+	mods int
+}
+
+var kittyFunctionalCodes = func() map[KeyType]int {
+	m := make(map[KeyType]int)
+	for code, _type := range kittyFunctional {
+		m[_type] = code
+	}
+	return m
+}()
+
+var kittyTildeCodes = func() map[KeyType]int {
+	m := make(map[KeyType]int)
+	for code, _type := range kittyTilde {
+		m[_type] = code
+	}
+	return m
+}()
+
+var kittyLetterCodes = func() map[KeyType]byte {
+	m := make(map[KeyType]byte)
+	for final, _type := range kittyLetter {
+		m[_type] = final
+	}
+	return m
+}()
+
+func encodeMods(key Key) (mods int) {
+	if key.Shift {
+		mods |= kittyShift
+	}
+	if key.Alt {
+		mods |= kittyAlt
+	}
+	if key.Ctrl {
+		mods |= kittyCtrl
+	}
+	if key.Super {
+		mods |= kittySuper
+	}
+	return
+}
+
+// toKittyCode decomposes the key into the representation used by the kitty
+// protocol. It returns false if the key has no such representation.
+func toKittyCode(key Key) (code kittyCode, ok bool) {
+	code.mods = encodeMods(key)
+	_type := key.Type
+
+	if base, ok := baseKeys[_type]; ok {
+		_type = base.base
+		if base.ctrl {
+			code.mods |= kittyCtrl
+		}
+		if base.shift {
+			code.mods |= kittyShift
+		}
+	}
+
+	switch {
+	case _type == KeyRunes || _type == KeySpace:
+		if len(key.Runes) != 1 {
+			return code, false
+		}
+
+		r := key.Runes[0]
+		code.code = int(r)
+		code.final = 'u'
+		code.text = true
+		if unicode.IsUpper(r) {
+			code.code = int(unicode.ToLower(r))
+			code.shifted = r
+			code.mods |= kittyShift
+		}
+		return code, true
+	case _type <= KeyF13 && _type >= KeyF20:
+		code.code = kittyF13 + int(KeyF13-_type)
+		code.final = 'u'
+		return code, true
+	}
+
+	if value, ok := kittyFunctionalCodes[_type]; ok {
+		code.code = value
+		code.final = 'u'
+		return code, true
+	}
+
+	if value, ok := kittyTildeCodes[_type]; ok {
+		code.code = value
+		code.final = '~'
+		return code, true
+	}
+
+	if final, ok := kittyLetterCodes[_type]; ok {
+		code.code = 1
+		code.final = final
+		return code, true
+	}
+
+	if r, ok := controlRunes[_type]; ok {
+		code.code = int(r)
+		code.final = 'u'
+		code.mods |= kittyCtrl
+		return code, true
+	}
+
+	return code, false
+}
+
+// KeysToKittyBytes encodes keys for an application that has requested
+// the kitty keyboard protocol `flags`. Keys that do not need to be
+// disambiguated are encoded the same way as KeysToBytes.
+func KeysToKittyBytes(flags emu.KeyFlag, keys ...KeyMsg) (data []byte, err error) {
+	reportEvents := flags&emu.KeyReportEvents != 0
+	reportAll := flags&emu.KeyReportAll != 0
+	disambiguate := flags&emu.KeyDisambiguate != 0 || reportAll
+
+	for _, key := range keys {
+		event := key.Event
+		if !reportEvents {
+			if event == KeyEventRelease {
+				continue
+			}
+			event = KeyEventPress
+		}
+
+		code, ok := toKittyCode(Key(key))
+		if !ok {
+			var legacy []byte
+			legacy, err = KeysToBytes(key)
+			if err != nil {
+				return
+			}
+			data = append(data, legacy...)
+			continue
+		}
+
+		// Determine whether the legacy encoding is sufficient. Keys
+		// that are not encoded with CSI u already have an unambiguous
+		// representation that encodeKitty produces.
+		legacy := code.final == 'u' && event == KeyEventPress && !reportAll
+		if legacy && disambiguate {
+			if code.text {
+				legacy = code.mods&^kittyShift == 0
+			} else {
+				// Escape is always ambiguous; enter, tab,
+				// backspace, and the rest are only ambiguous
+				// with modifiers
+				legacy = code.code != 27 && code.mods == 0
+			}
+		}
+
+		if legacy {
+			var bytes []byte
+			bytes, err = KeysToBytes(KeyMsg{
+				Type:  key.Type,
+				Runes: key.Runes,
+				Alt:   key.Alt,
+				Ctrl:  key.Ctrl,
+				Shift: key.Shift,
+				Super: key.Super,
+			})
+			if err != nil {
+				return
+			}
+			data = append(data, bytes...)
+			continue
+		}
+
+		data = append(data, encodeKitty(flags, code, event)...)
+	}
+	return
+}
+
+func encodeKitty(flags emu.KeyFlag, code kittyCode, event KeyEvent) []byte {
+	var b strings.Builder
+	b.WriteString("\x1b[")
+
+	// The xterm-style sequences, such as CSI A, omit the key number
+	// when there is nothing else to report
+	if code.final == 'u' || code.code != 1 || code.mods != 0 || event != KeyEventPress {
+		b.WriteString(strconv.Itoa(code.code))
+	}
+
+	if code.final == 'u' && flags&emu.KeyReportAlternates != 0 && code.shifted != 0 {
+		b.WriteString(":" + strconv.Itoa(int(code.shifted)))
+	}
+
+	if code.mods != 0 || event != KeyEventPress {
+		b.WriteString(";" + strconv.Itoa(code.mods+1))
+		if event != KeyEventPress {
+			b.WriteString(":" + strconv.Itoa(int(event)+1))
+		}
+	}
+
+	b.WriteByte(code.final)
+	return []byte(b.String())
+}
@@ -145,6 +145,28 @@ func (m MouseEvent) String() (s string) {
 	return s
 }
 
+// Key returns the name that refers to this mouse event in key sequences,
+// e.g. "mouse-left" or "alt+wheel-up". Only button presses and scroll wheel
+// events can be bound, so ok is false for all other events.
+func (m MouseEvent) Key() (key string, ok bool) {
+	if m.Type != MousePress || !m.Down {
+		return "", false
+	}
+
+	name, ok := mouseButtonKeys[m.Button]
+	if !ok {
+		return "", false
+	}
+
+	if m.Alt {
+		key += "alt+"
+	}
+	if m.Ctrl {
+		key += "ctrl+"
+	}
+	return key + name, true
+}
+
 // MouseEventType indicates the type of mouse event occurring.
 type MouseEventType int
 
@@ -183,6 +205,16 @@ var mouseButtonTypes = map[MouseButton]string{
 	MouseWheelRight: "wheel right",
 }
 
+var mouseButtonKeys = map[MouseButton]string{
+	MouseLeft:       "mouse-left",
+	MouseRight:      "mouse-right",
+	MouseMiddle:     "mouse-middle",
+	MouseWheelUp:    "wheel-up",
+	MouseWheelDown:  "wheel-down",
+	MouseWheelLeft:  "wheel-left",
+	MouseWheelRight: "wheel-right",
+}
+
 const (
 	byteOffset = 32
 
@@ -195,6 +195,12 @@ func (p *Program) eventLoop(model Model, cmds chan Cmd) (Model, error) {
 				p.Publish(msg.Msg)
 				continue
 
+			case KeyMsg:
+				// Models are only interested in key presses
+				if msg.Event == KeyEventRelease {
+					continue
+				}
+
 			case tea.BatchMsg:
 				for _, cmd := range msg {
 					cmds <- cmd